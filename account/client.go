@@ -19,10 +19,11 @@ func newAccountClient(client *client.Client) *AccountClient {
 // resources within CloudAPI
 func NewClient(config *triton.ClientConfig) (*AccountClient, error) {
 	// TODO: Utilize config interface within the function itself
-	client, err := client.New(config.TritonURL, config.MantaURL, config.AccountName, config.Signers...)
+	client, err := client.NewForUser(config.TritonURL, config.MantaURL, config.AccountName, config.Username, config.Signers...)
 	if err != nil {
 		return nil, err
 	}
+	client.AsRole = config.AsRole
 	return newAccountClient(client), nil
 }
 
@@ -37,3 +38,15 @@ func (c *AccountClient) Config() *ConfigClient {
 func (c *AccountClient) Keys() *KeysClient {
 	return &KeysClient{c.Client}
 }
+
+// Limits returns a LimitsClient used for accessing functions pertaining to
+// provisioning limits in the Triton API.
+func (c *AccountClient) Limits() *LimitsClient {
+	return &LimitsClient{c.Client}
+}
+
+// Usage returns a UsageClient used for summarizing the resources used by the
+// instances of the account.
+func (c *AccountClient) Usage() *UsageClient {
+	return &UsageClient{c.Client}
+}
@@ -0,0 +1,52 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/compute"
+)
+
+// ErrCNSDisabled is returned when CNS names are requested for an account which
+// does not have Triton CNS enabled.
+var ErrCNSDisabled = errors.New("Triton CNS is not enabled for the account")
+
+// InstanceCNSNamesInput represents parameters to an InstanceCNSNames
+// operation.
+type InstanceCNSNamesInput struct {
+	Instance *compute.Instance
+
+	// Datacenter is the name of the datacenter of the instance. Required.
+	Datacenter string
+
+	// Zone and Suffix select the CNS zone, see compute.CNSNamesInput.
+	Zone   string
+	Suffix string
+}
+
+// InstanceCNSNames returns the DNS names CNS gives an instance of the account,
+// or ErrCNSDisabled if Triton CNS is not enabled for the account.
+func (c *AccountClient) InstanceCNSNames(ctx context.Context, input *InstanceCNSNamesInput) (*compute.CNSNames, error) {
+	if input.Instance == nil {
+		return nil, errors.New("Instance is required to compute CNS names")
+	}
+
+	acct, err := c.Get(ctx, &GetInput{})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing InstanceCNSNames request: {{err}}", err)
+	}
+	if !acct.TritonCNSEnabled {
+		return nil, ErrCNSDisabled
+	}
+
+	return compute.CNSNamesFor(&compute.CNSNamesInput{
+		AccountID:    acct.ID,
+		InstanceID:   input.Instance.ID,
+		InstanceName: input.Instance.Name,
+		CNS:          input.Instance.CNS,
+		Datacenter:   input.Datacenter,
+		Zone:         input.Zone,
+		Suffix:       input.Suffix,
+	})
+}
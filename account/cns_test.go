@@ -0,0 +1,19 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joyent/triton-go/client"
+)
+
+func TestInstanceCNSNames_NilInstance(t *testing.T) {
+	// The input is checked before any request is made, so no server is
+	// needed.
+	c := &AccountClient{Client: &client.Client{AccountName: "acct"}}
+
+	_, err := c.InstanceCNSNames(context.Background(), &InstanceCNSNamesInput{Datacenter: "us-east-1"})
+	if err == nil {
+		t.Fatal("expected an error for a nil Instance")
+	}
+}
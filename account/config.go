@@ -14,10 +14,98 @@ type ConfigClient struct {
 	client *client.Client
 }
 
+// ConfigKeyDefaultNetwork is the config key of Config.DefaultNetwork.
+const ConfigKeyDefaultNetwork = "default_network"
+
 // Config represents configuration for your account.
 type Config struct {
 	// DefaultNetwork is the network that docker containers are provisioned on.
-	DefaultNetwork string `json:"default_network"`
+	DefaultNetwork string
+
+	// Extra holds every other key CloudAPI returns, so that settings this
+	// package does not model yet survive a round trip.
+	Extra map[string]interface{}
+
+	// hasDefaultNetwork records whether CloudAPI returned default_network,
+	// so that an absent key is not reported as an empty network.
+	hasDefaultNetwork bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*c = Config{Extra: map[string]interface{}{}}
+	for key, value := range values {
+		if key == ConfigKeyDefaultNetwork {
+			network, ok := value.(string)
+			if !ok && value != nil {
+				return fmt.Errorf("config key %s is not a string", key)
+			}
+			c.DefaultNetwork = network
+			c.hasDefaultNetwork = true
+			continue
+		}
+		c.Extra[key] = value
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.values())
+}
+
+// values returns every key of the config. default_network is included only if
+// CloudAPI returned it or DefaultNetwork has been set.
+func (c Config) values() map[string]interface{} {
+	values := map[string]interface{}{}
+	for key, value := range c.Extra {
+		values[key] = value
+	}
+	if c.hasDefaultNetwork || c.DefaultNetwork != "" {
+		values[ConfigKeyDefaultNetwork] = c.DefaultNetwork
+	}
+	return values
+}
+
+// Get returns the value of a config key, typed or extra.
+func (c *Config) Get(key string) (interface{}, bool) {
+	value, ok := c.values()[key]
+	return value, ok
+}
+
+// GetString returns the value of a config key if it is a string.
+func (c *Config) GetString(key string) (string, bool) {
+	value, ok := c.Get(key)
+	if !ok {
+		return "", false
+	}
+	str, ok := value.(string)
+	return str, ok
+}
+
+// GetBool returns the value of a config key if it is a boolean.
+func (c *Config) GetBool(key string) (bool, bool) {
+	value, ok := c.Get(key)
+	if !ok {
+		return false, false
+	}
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// GetNumber returns the value of a config key if it is a number.
+func (c *Config) GetNumber(key string) (float64, bool) {
+	value, ok := c.Get(key)
+	if !ok {
+		return 0, false
+	}
+	n, ok := value.(float64)
+	return n, ok
 }
 
 type GetConfigInput struct{}
@@ -46,9 +134,26 @@ func (c *ConfigClient) Get(ctx context.Context, input *GetConfigInput) (*Config,
 	return result, nil
 }
 
+// UpdateConfigInput represents the config keys to update. Keys which are not
+// set are left unchanged.
 type UpdateConfigInput struct {
 	// DefaultNetwork is the network that docker containers are provisioned on.
-	DefaultNetwork string `json:"default_network"`
+	DefaultNetwork string
+
+	// Extra sets keys which are not modelled by this package.
+	Extra map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler, encoding only the keys which are set.
+func (input *UpdateConfigInput) MarshalJSON() ([]byte, error) {
+	values := map[string]interface{}{}
+	for key, value := range input.Extra {
+		values[key] = value
+	}
+	if input.DefaultNetwork != "" {
+		values[ConfigKeyDefaultNetwork] = input.DefaultNetwork
+	}
+	return json.Marshal(values)
 }
 
 // UpdateConfig updates configuration values for your account.
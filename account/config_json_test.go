@@ -0,0 +1,122 @@
+package account
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_RoundTrip(t *testing.T) {
+	data := []byte(`{"default_network":"net-1","docker_cns":true,"max_retries":3,"label":"ci"}`)
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("error decoding config: %s", err)
+	}
+	if config.DefaultNetwork != "net-1" {
+		t.Fatalf("unexpected default network %q", config.DefaultNetwork)
+	}
+	if _, ok := config.Extra[ConfigKeyDefaultNetwork]; ok {
+		t.Fatal("default network should not be kept in Extra")
+	}
+
+	if network, ok := config.GetString(ConfigKeyDefaultNetwork); !ok || network != "net-1" {
+		t.Fatalf("unexpected GetString result %q, %v", network, ok)
+	}
+	if cns, ok := config.GetBool("docker_cns"); !ok || !cns {
+		t.Fatalf("unexpected GetBool result %v, %v", cns, ok)
+	}
+	if retries, ok := config.GetNumber("max_retries"); !ok || retries != 3 {
+		t.Fatalf("unexpected GetNumber result %v, %v", retries, ok)
+	}
+	if _, ok := config.GetBool("label"); ok {
+		t.Fatal("GetBool should fail on a string value")
+	}
+	if _, ok := config.Get("missing"); ok {
+		t.Fatal("Get should fail on a missing key")
+	}
+
+	encoded, err := json.Marshal(&config)
+	if err != nil {
+		t.Fatalf("error encoding config: %s", err)
+	}
+	var got, want map[string]interface{}
+	json.Unmarshal(encoded, &got)
+	json.Unmarshal(data, &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("config did not round trip: got %s", encoded)
+	}
+}
+
+func TestUpdateConfigInput_Partial(t *testing.T) {
+	cases := []struct {
+		input *UpdateConfigInput
+		want  string
+	}{
+		{&UpdateConfigInput{}, `{}`},
+		{&UpdateConfigInput{DefaultNetwork: "net-2"}, `{"default_network":"net-2"}`},
+		{
+			&UpdateConfigInput{Extra: map[string]interface{}{"docker_cns": false}},
+			`{"docker_cns":false}`,
+		},
+	}
+
+	for _, c := range cases {
+		encoded, err := json.Marshal(c.input)
+		if err != nil {
+			t.Fatalf("error encoding input: %s", err)
+		}
+		if string(encoded) != c.want {
+			t.Fatalf("expected %s, got %s", c.want, encoded)
+		}
+	}
+}
+
+func TestConfig_RoundTripWithoutDefaultNetwork(t *testing.T) {
+	data := []byte(`{"docker_cns":false}`)
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("error decoding config: %s", err)
+	}
+	if _, ok := config.Get(ConfigKeyDefaultNetwork); ok {
+		t.Fatal("Get should fail on an absent default network")
+	}
+	if _, ok := config.GetString(ConfigKeyDefaultNetwork); ok {
+		t.Fatal("GetString should fail on an absent default network")
+	}
+
+	// Marshaling a value rather than a pointer must use MarshalJSON too.
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("error encoding config: %s", err)
+	}
+	if string(encoded) != string(data) {
+		t.Fatalf("config did not round trip: got %s", encoded)
+	}
+
+	config.DefaultNetwork = "net-1"
+	if network, ok := config.GetString(ConfigKeyDefaultNetwork); !ok || network != "net-1" {
+		t.Fatalf("unexpected GetString result %q, %v", network, ok)
+	}
+}
+
+func TestConfig_EmptyDefaultNetwork(t *testing.T) {
+	data := []byte(`{"default_network":""}`)
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("error decoding config: %s", err)
+	}
+	if network, ok := config.GetString(ConfigKeyDefaultNetwork); !ok || network != "" {
+		t.Fatalf("expected a present, empty default network, got %q, %v", network, ok)
+	}
+
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("error encoding config: %s", err)
+	}
+	if string(encoded) != string(data) {
+		t.Fatalf("config did not round trip: got %s", encoded)
+	}
+}
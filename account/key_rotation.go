@@ -0,0 +1,149 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/authentication"
+)
+
+const (
+	defaultRotateVerifyAttempts = 5
+	defaultRotateVerifyInterval = 2 * time.Second
+)
+
+// RotateKeyInput represents parameters to a RotateKey operation.
+type RotateKeyInput struct {
+	// OldKeyName is the name or fingerprint of the key to retire. Required.
+	OldKeyName string
+
+	// NewKeyName is the name of the new key. Optional.
+	NewKeyName string
+
+	// KeyType is one of the authentication.KeyType constants. Defaults to
+	// RSA.
+	KeyType string
+
+	// KeyBits is the size of the new key, see authentication.GenerateKeyPair.
+	KeyBits int
+
+	// VerifyAttempts is how many requests signed with the new key are tried
+	// before giving up, since a new key can take a moment to be usable.
+	// Defaults to 5.
+	VerifyAttempts int
+
+	// VerifyInterval is the time between verification attempts. Defaults to
+	// 2 seconds.
+	VerifyInterval time.Duration
+
+	// KeepOldKey leaves the old key in place once the new one is verified.
+	KeepOldKey bool
+}
+
+// RotateKeyOutput is the result of a successful key rotation.
+type RotateKeyOutput struct {
+	// Key is the new key as recorded by Triton.
+	Key *Key
+
+	// KeyPair holds the private key material of the new key, which is not
+	// stored anywhere else.
+	KeyPair *authentication.KeyPair
+
+	// Signer signs requests with the new key. Clients signing with the old
+	// key should switch to it.
+	Signer *authentication.PrivateKeySigner
+}
+
+// Rotate replaces a key of the account with a newly generated one. The new key
+// is uploaded and verified by making a request signed with it before the old
+// key is deleted. If verification fails, the new key is deleted again and the
+// old key is left untouched. If only deleting the old key fails, the output is
+// returned along with the error, since the new key is already in use.
+func (c *KeysClient) Rotate(ctx context.Context, input *RotateKeyInput) (*RotateKeyOutput, error) {
+	if input.OldKeyName == "" {
+		return nil, errors.New("Error executing RotateKey request: OldKeyName is required")
+	}
+	keyType := input.KeyType
+	if keyType == "" {
+		keyType = authentication.KeyTypeRSA
+	}
+	attempts := input.VerifyAttempts
+	if attempts <= 0 {
+		attempts = defaultRotateVerifyAttempts
+	}
+	interval := input.VerifyInterval
+	if interval <= 0 {
+		interval = defaultRotateVerifyInterval
+	}
+
+	if _, err := c.Get(ctx, &GetKeyInput{KeyName: input.OldKeyName}); err != nil {
+		return nil, errwrap.Wrapf("Error executing RotateKey request: {{err}}", err)
+	}
+
+	keyPair, err := authentication.GenerateKeyPair(keyType, input.KeyBits, input.NewKeyName)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing RotateKey request: {{err}}", err)
+	}
+	signer, err := authentication.NewPrivateKeySigner(keyPair.Fingerprint, keyPair.PrivateKeyMaterial, c.client.AccountName)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing RotateKey request: {{err}}", err)
+	}
+
+	key, err := c.Create(ctx, &CreateKeyInput{
+		Name: input.NewKeyName,
+		Key:  keyPair.PublicKey,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing RotateKey request: {{err}}", err)
+	}
+
+	newClient := *c.client
+	newClient.Authorizers = []authentication.Signer{signer}
+	newKeys := &KeysClient{&newClient}
+
+	if err := verifyKey(ctx, newKeys, keyPair.Fingerprint, attempts, interval); err != nil {
+		verifyErr := errwrap.Wrapf("Error verifying new key: {{err}}", err)
+		deleteErr := c.Delete(context.Background(), &DeleteKeyInput{KeyName: keyPair.Fingerprint})
+		if deleteErr != nil {
+			return nil, fmt.Errorf("%s; rolling back new key %s also failed: %s",
+				verifyErr, keyPair.Fingerprint, deleteErr)
+		}
+		return nil, verifyErr
+	}
+
+	output := &RotateKeyOutput{
+		Key:     key,
+		KeyPair: keyPair,
+		Signer:  signer,
+	}
+
+	if !input.KeepOldKey {
+		if err := newKeys.Delete(ctx, &DeleteKeyInput{KeyName: input.OldKeyName}); err != nil {
+			return output, errwrap.Wrapf("Error deleting old key after rotation: {{err}}", err)
+		}
+	}
+
+	return output, nil
+}
+
+// verifyKey makes requests signed with the new key until one succeeds.
+func verifyKey(ctx context.Context, keys *KeysClient, fingerprint string, attempts int, interval time.Duration) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if _, err = keys.Get(ctx, &GetKeyInput{KeyName: fingerprint}); err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
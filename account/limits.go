@@ -0,0 +1,347 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+	"github.com/joyent/triton-go/compute"
+)
+
+// Values of Limit.Check, which select the instances a limit counts.
+const (
+	LimitCheckImage = "image"
+	LimitCheckOS    = "os"
+	LimitCheckBrand = "brand"
+)
+
+// Values of Limit.By, which select what a limit counts.
+const (
+	LimitByMachines = "machines"
+	LimitByRAM      = "ram"
+	LimitByQuota    = "quota"
+)
+
+type LimitsClient struct {
+	client *client.Client
+}
+
+// Limit is a provisioning limit of the account.
+type Limit struct {
+	// Datacenter is the datacenter the limit applies in, if reported.
+	Datacenter string `json:"datacenter,omitempty"`
+
+	// Check selects the instances counted against the limit: those with the
+	// image, OS or brand given below, or every instance if empty.
+	Check string `json:"check,omitempty"`
+
+	// By is what is counted: the number of instances ("machines", the
+	// default), their memory in MiB ("ram") or their disk in GiB ("quota").
+	By string `json:"by,omitempty"`
+
+	// Value is the most that may be provisioned. Negative values are
+	// unlimited.
+	Value int64 `json:"value"`
+
+	Image string `json:"image,omitempty"`
+	OS    string `json:"os,omitempty"`
+	Brand string `json:"brand,omitempty"`
+}
+
+func (l *Limit) String() string {
+	by := l.By
+	if by == "" {
+		by = LimitByMachines
+	}
+
+	var selector string
+	switch l.Check {
+	case LimitCheckImage:
+		selector = fmt.Sprintf(" with image %s", l.Image)
+	case LimitCheckOS:
+		selector = fmt.Sprintf(" with OS %s", l.OS)
+	case LimitCheckBrand:
+		selector = fmt.Sprintf(" with brand %s", l.Brand)
+	}
+
+	limit := fmt.Sprintf("%d %s%s", l.Value, by, selector)
+	if l.Datacenter != "" {
+		limit = fmt.Sprintf("%s in %s", limit, l.Datacenter)
+	}
+	return limit
+}
+
+// limitSubject is an existing or planned instance counted against limits.
+type limitSubject struct {
+	imageID   string
+	imageName string
+	os        string
+	brand     string
+	memory    int64
+	disk      int64
+}
+
+func (l *Limit) validate() error {
+	switch l.Check {
+	case "", LimitCheckImage, LimitCheckOS, LimitCheckBrand:
+	default:
+		return fmt.Errorf("unsupported limit check %q", l.Check)
+	}
+	switch l.By {
+	case "", LimitByMachines, LimitByRAM, LimitByQuota:
+	default:
+		return fmt.Errorf("unsupported limit by %q", l.By)
+	}
+	return nil
+}
+
+func (l *Limit) appliesTo(subject *limitSubject) bool {
+	switch l.Check {
+	case LimitCheckImage:
+		return l.Image != "" && (l.Image == subject.imageID || l.Image == subject.imageName)
+	case LimitCheckOS:
+		return l.OS == subject.os
+	case LimitCheckBrand:
+		return l.Brand == subject.brand
+	}
+	return true
+}
+
+// amount returns how much subject counts against the limit. Quota is counted
+// in MiB and converted to GiB once totalled.
+func (l *Limit) amount(subject *limitSubject) int64 {
+	switch l.By {
+	case LimitByRAM:
+		return subject.memory
+	case LimitByQuota:
+		return subject.disk
+	}
+	return 1
+}
+
+// toUnit converts an amount returned by Limit.amount to the unit of Value.
+func (l *Limit) toUnit(amount int64) int64 {
+	if l.By == LimitByQuota {
+		return (amount + 1023) / 1024
+	}
+	return amount
+}
+
+// exceeded reports whether amount, as returned by Limit.amount, is over the
+// limit.
+func (l *Limit) exceeded(amount int64) bool {
+	if l.Value < 0 {
+		return false
+	}
+	if l.By == LimitByQuota {
+		return amount > l.Value*1024
+	}
+	return amount > l.Value
+}
+
+type ListLimitsInput struct{}
+
+// List lists the provisioning limits of the account.
+func (c *LimitsClient) List(ctx context.Context, _ *ListLimitsInput) ([]*Limit, error) {
+	path := fmt.Sprintf("/%s/limits", c.client.AccountName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListLimits request: {{err}}", err)
+	}
+
+	var result []*Limit
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ListLimits response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// LimitViolation is a limit which a planned batch of instances would exceed.
+// Current and Planned are in the unit of the limit.
+type LimitViolation struct {
+	Limit *Limit
+
+	// Current is the amount already used by existing instances.
+	Current int64
+
+	// Planned is the amount the planned instances would add.
+	Planned int64
+}
+
+func (v *LimitViolation) String() string {
+	return fmt.Sprintf("limit of %s exceeded: %d in use, %d planned",
+		v.Limit, v.Current, v.Planned)
+}
+
+// CheckLimitsInput represents parameters to CheckLimits.
+type CheckLimitsInput struct {
+	Limits []*Limit
+
+	// Instances are the existing instances of the account.
+	Instances []*compute.Instance
+
+	// Planned are the instances which are about to be created.
+	Planned []*compute.CreateInstanceInput
+
+	// Packages and Images are used to look up the memory, disk, image name,
+	// OS and brand of planned instances, and the image name and OS of
+	// existing ones.
+	Packages []*compute.Package
+	Images   []*compute.Image
+
+	// Datacenter is the datacenter instances are planned in. If set, limits
+	// which report another datacenter are ignored.
+	Datacenter string
+}
+
+// CheckLimits returns the limits which creating every planned instance would
+// exceed. An empty result means the whole batch fits.
+func CheckLimits(input *CheckLimitsInput) ([]*LimitViolation, error) {
+	images := map[string]*compute.Image{}
+	for _, image := range input.Images {
+		images[image.ID] = image
+	}
+	packages := map[string]*compute.Package{}
+	for _, pkg := range input.Packages {
+		packages[pkg.ID] = pkg
+		packages[pkg.Name] = pkg
+	}
+
+	var current []*limitSubject
+	for _, instance := range input.Instances {
+		subject := &limitSubject{
+			imageID: instance.Image,
+			brand:   instance.Brand,
+			memory:  int64(instance.Memory),
+			disk:    int64(instance.Disk),
+		}
+		if image, ok := images[instance.Image]; ok {
+			subject.imageName = image.Name
+			subject.os = image.OS
+		}
+		current = append(current, subject)
+	}
+
+	var planned []*limitSubject
+	for _, instance := range input.Planned {
+		pkg, ok := packages[instance.Package]
+		if !ok {
+			return nil, fmt.Errorf("package %q of planned instance %q not found", instance.Package, instance.Name)
+		}
+		image, ok := images[instance.Image]
+		if !ok {
+			return nil, fmt.Errorf("image %q of planned instance %q not found", instance.Image, instance.Name)
+		}
+		planned = append(planned, &limitSubject{
+			imageID:   image.ID,
+			imageName: image.Name,
+			os:        image.OS,
+			brand:     imageBrand(image),
+			memory:    pkg.Memory,
+			disk:      pkg.Disk,
+		})
+	}
+
+	var violations []*LimitViolation
+	for _, limit := range input.Limits {
+		if err := limit.validate(); err != nil {
+			return nil, err
+		}
+		if input.Datacenter != "" && limit.Datacenter != "" && limit.Datacenter != input.Datacenter {
+			continue
+		}
+
+		var currentAmount, plannedAmount int64
+		for _, subject := range current {
+			if limit.appliesTo(subject) {
+				currentAmount += limit.amount(subject)
+			}
+		}
+		for _, subject := range planned {
+			if limit.appliesTo(subject) {
+				plannedAmount += limit.amount(subject)
+			}
+		}
+
+		if plannedAmount > 0 && limit.exceeded(currentAmount+plannedAmount) {
+			violations = append(violations, &LimitViolation{
+				Limit:   limit,
+				Current: limit.toUnit(currentAmount),
+				Planned: limit.toUnit(plannedAmount),
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// imageBrand returns the brand of instances created from image.
+func imageBrand(image *compute.Image) string {
+	if brand, ok := image.Requirements["brand"].(string); ok && brand != "" {
+		return brand
+	}
+	switch image.Type {
+	case "zone-dataset":
+		return "joyent"
+	case "lx-dataset", "docker":
+		return "lx"
+	case "zvol":
+		return "kvm"
+	}
+	return strings.TrimSuffix(image.Type, "-dataset")
+}
+
+// PreflightInput represents parameters to a Preflight operation.
+type PreflightInput struct {
+	// Instances are the instances which are about to be created.
+	Instances []*compute.CreateInstanceInput
+
+	// Datacenter is the datacenter of the client, if known.
+	Datacenter string
+}
+
+// Preflight checks that a batch of instances can be created without
+// exceeding the provisioning limits of the account, before any of them is.
+func (c *LimitsClient) Preflight(ctx context.Context, input *PreflightInput) ([]*LimitViolation, error) {
+	limits, err := c.List(ctx, &ListLimitsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	computeClient := &compute.ComputeClient{Client: c.client}
+	instances, err := computeClient.Instances().ListAll(ctx, nil)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error listing instances for Preflight: {{err}}", err)
+	}
+
+	packages, err := computeClient.Packages().List(ctx, &compute.ListPackagesInput{})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error listing packages for Preflight: {{err}}", err)
+	}
+	images, err := computeClient.Images().List(ctx, &compute.ListImagesInput{})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error listing images for Preflight: {{err}}", err)
+	}
+
+	return CheckLimits(&CheckLimitsInput{
+		Limits:     limits,
+		Instances:  instances,
+		Planned:    input.Instances,
+		Packages:   packages,
+		Images:     images,
+		Datacenter: input.Datacenter,
+	})
+}
@@ -0,0 +1,111 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/joyent/triton-go/compute"
+)
+
+func testLimitsInput() *CheckLimitsInput {
+	return &CheckLimitsInput{
+		Instances: []*compute.Instance{
+			{ID: "i1", Image: "img-base", Brand: "joyent", Memory: 1024, Disk: 25600},
+			{ID: "i2", Image: "img-win", Brand: "kvm", Memory: 4096, Disk: 51200},
+		},
+		Packages: []*compute.Package{
+			{ID: "pkg-small", Name: "g4-highcpu-1G", Memory: 1024, Disk: 25600},
+			{ID: "pkg-large", Name: "g4-highcpu-4G", Memory: 4096, Disk: 102400},
+		},
+		Images: []*compute.Image{
+			{ID: "img-base", Name: "base-64", OS: "smartos", Type: "zone-dataset"},
+			{ID: "img-win", Name: "windows", OS: "windows", Type: "zvol"},
+		},
+	}
+}
+
+func TestCheckLimits_Fits(t *testing.T) {
+	input := testLimitsInput()
+	input.Limits = []*Limit{
+		{Value: 5},
+		{By: LimitByRAM, Value: 8192},
+		{Check: LimitCheckOS, OS: "windows", Value: 1},
+	}
+	input.Planned = []*compute.CreateInstanceInput{
+		{Name: "web0", Package: "g4-highcpu-1G", Image: "img-base"},
+		{Name: "web1", Package: "pkg-small", Image: "img-base"},
+	}
+
+	violations, err := CheckLimits(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
+
+func TestCheckLimits_Exceeded(t *testing.T) {
+	input := testLimitsInput()
+	input.Limits = []*Limit{
+		{Value: 3},
+		{Check: LimitCheckOS, OS: "windows", Value: 1},
+		{Check: LimitCheckBrand, Brand: "joyent", By: LimitByQuota, Value: 100},
+		{Check: LimitCheckImage, Image: "base-64", Value: -1},
+		{Datacenter: "us-west-1", Value: 0},
+	}
+	input.Datacenter = "us-east-1"
+	input.Planned = []*compute.CreateInstanceInput{
+		{Name: "web0", Package: "pkg-large", Image: "img-base"},
+		{Name: "win0", Package: "pkg-small", Image: "img-win"},
+	}
+
+	violations, err := CheckLimits(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(violations) != 3 {
+		t.Fatalf("expected 3 violations, got %v", violations)
+	}
+
+	machines := violations[0]
+	if machines.Limit != input.Limits[0] || machines.Current != 2 || machines.Planned != 2 {
+		t.Fatalf("unexpected machines violation: %s", machines)
+	}
+	windows := violations[1]
+	if windows.Limit != input.Limits[1] || windows.Current != 1 || windows.Planned != 1 {
+		t.Fatalf("unexpected windows violation: %s", windows)
+	}
+	quota := violations[2]
+	if quota.Limit != input.Limits[2] || quota.Current != 25 || quota.Planned != 100 {
+		t.Fatalf("unexpected quota violation: %s", quota)
+	}
+}
+
+func TestCheckLimits_Errors(t *testing.T) {
+	input := testLimitsInput()
+	input.Planned = []*compute.CreateInstanceInput{
+		{Name: "web0", Package: "missing", Image: "img-base"},
+	}
+	if _, err := CheckLimits(input); err == nil {
+		t.Fatal("expected error for unknown package")
+	}
+
+	input = testLimitsInput()
+	input.Limits = []*Limit{{By: "cpu", Value: 1}}
+	if _, err := CheckLimits(input); err == nil {
+		t.Fatal("expected error for unsupported limit")
+	}
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	summary := SummarizeUsage(testLimitsInput().Instances)
+	if summary.Total.Instances != 2 || summary.Total.Memory != 5120 || summary.Total.Disk != 76800 {
+		t.Fatalf("unexpected total: %+v", summary.Total)
+	}
+	if kvm := summary.ByBrand["kvm"]; kvm == nil || kvm.Memory != 4096 {
+		t.Fatalf("unexpected kvm usage: %+v", kvm)
+	}
+	if base := summary.ByImage["img-base"]; base == nil || base.Instances != 1 {
+		t.Fatalf("unexpected image usage: %+v", base)
+	}
+}
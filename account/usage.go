@@ -0,0 +1,85 @@
+package account
+
+import (
+	"context"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+	"github.com/joyent/triton-go/compute"
+)
+
+type UsageClient struct {
+	client *client.Client
+}
+
+// Usage is the amount of resources used by a set of instances.
+type Usage struct {
+	Instances int64
+
+	// Memory is the total memory of the instances, in MiB.
+	Memory int64
+
+	// Disk is the total disk of the instances, in MiB.
+	Disk int64
+}
+
+func (u *Usage) add(instance *compute.Instance) {
+	u.Instances++
+	u.Memory += int64(instance.Memory)
+	u.Disk += int64(instance.Disk)
+}
+
+// UsageSummary is the amount of resources used by the instances of an
+// account, in total and broken down by a few instance attributes.
+type UsageSummary struct {
+	Total Usage
+
+	// ByBrand, ByPackage, ByImage and ByState are keyed by the brand, package
+	// name, image ID and state of the instances respectively.
+	ByBrand   map[string]*Usage
+	ByPackage map[string]*Usage
+	ByImage   map[string]*Usage
+	ByState   map[string]*Usage
+}
+
+// SummarizeUsage returns the resources used by instances.
+func SummarizeUsage(instances []*compute.Instance) *UsageSummary {
+	summary := &UsageSummary{
+		ByBrand:   map[string]*Usage{},
+		ByPackage: map[string]*Usage{},
+		ByImage:   map[string]*Usage{},
+		ByState:   map[string]*Usage{},
+	}
+
+	addTo := func(usages map[string]*Usage, key string, instance *compute.Instance) {
+		usage, ok := usages[key]
+		if !ok {
+			usage = &Usage{}
+			usages[key] = usage
+		}
+		usage.add(instance)
+	}
+
+	for _, instance := range instances {
+		summary.Total.add(instance)
+		addTo(summary.ByBrand, instance.Brand, instance)
+		addTo(summary.ByPackage, instance.Package, instance)
+		addTo(summary.ByImage, instance.Image, instance)
+		addTo(summary.ByState, instance.State, instance)
+	}
+
+	return summary
+}
+
+type GetUsageInput struct{}
+
+// Get returns a summary of the resources used by every instance of the
+// account.
+func (c *UsageClient) Get(ctx context.Context, _ *GetUsageInput) (*UsageSummary, error) {
+	instances, err := (&compute.ComputeClient{Client: c.client}).Instances().ListAll(ctx, nil)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetUsage request: {{err}}", err)
+	}
+
+	return SummarizeUsage(instances), nil
+}
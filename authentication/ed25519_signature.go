@@ -0,0 +1,28 @@
+package authentication
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+type ed25519Signature struct {
+	signature []byte
+}
+
+func (s *ed25519Signature) SignatureType() string {
+	return "ed25519-sha512"
+}
+
+func (s *ed25519Signature) String() string {
+	return base64.StdEncoding.EncodeToString(s.signature)
+}
+
+func newED25519Signature(signatureBlob []byte) (*ed25519Signature, error) {
+	if len(signatureBlob) != 64 {
+		return nil, fmt.Errorf("Invalid Ed25519 signature length: %d", len(signatureBlob))
+	}
+
+	return &ed25519Signature{
+		signature: signatureBlob,
+	}, nil
+}
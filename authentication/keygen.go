@@ -0,0 +1,128 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"golang.org/x/crypto/ssh"
+)
+
+// Types of key pairs GenerateKeyPair can generate.
+const (
+	KeyTypeRSA     = "rsa"
+	KeyTypeECDSA   = "ecdsa"
+	KeyTypeEd25519 = "ed25519"
+)
+
+const (
+	defaultRSABits   = 2048
+	defaultECDSABits = 256
+)
+
+// KeyPair is a newly generated key pair.
+type KeyPair struct {
+	// PrivateKeyMaterial is the PEM-encoded private key, as accepted by
+	// NewPrivateKeySigner.
+	PrivateKeyMaterial []byte
+
+	// PublicKey is the OpenSSH-formatted public key, as uploaded to Triton.
+	PublicKey string
+
+	// Fingerprint is the MD5 fingerprint of the public key, with colons
+	// between each byte.
+	Fingerprint string
+}
+
+// GenerateKeyPair generates a key pair of keyType, one of the KeyType
+// constants. bits is the size of RSA keys (2048 by default) or the curve size
+// of ECDSA keys (256, 384 or 521, 256 by default), and is ignored for Ed25519
+// keys. comment is appended to the public key.
+func GenerateKeyPair(keyType string, bits int, comment string) (*KeyPair, error) {
+	var privateKey crypto.Signer
+	var block *pem.Block
+
+	switch keyType {
+	case KeyTypeRSA:
+		if bits == 0 {
+			bits = defaultRSABits
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error generating RSA key: {{err}}", err)
+		}
+		privateKey = key
+		block = &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}
+
+	case KeyTypeECDSA:
+		var curve elliptic.Curve
+		switch bits {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("Unsupported ECDSA key size: %d", bits)
+		}
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error generating ECDSA key: {{err}}", err)
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error marshaling ECDSA key: {{err}}", err)
+		}
+		privateKey = key
+		block = &pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		}
+
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error generating Ed25519 key: {{err}}", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error marshaling Ed25519 key: {{err}}", err)
+		}
+		privateKey = key
+		block = &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: der,
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported key type: %s", keyType)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, errwrap.Wrapf("Error converting public key: {{err}}", err)
+	}
+
+	publicKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshPublicKey)), "\n")
+	if comment != "" {
+		publicKey = fmt.Sprintf("%s %s", publicKey, comment)
+	}
+
+	return &KeyPair{
+		PrivateKeyMaterial: pem.EncodeToMemory(block),
+		PublicKey:          publicKey,
+		Fingerprint:        formatPublicKeyFingerprint(sshPublicKey, true),
+	}, nil
+}
@@ -0,0 +1,70 @@
+package authentication
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKeyPair_Sign(t *testing.T) {
+	cases := []struct {
+		keyType   string
+		bits      int
+		algorithm string
+	}{
+		{KeyTypeRSA, 0, "rsa-sha1"},
+		{KeyTypeECDSA, 0, "ecdsa-sha256"},
+		{KeyTypeECDSA, 384, "ecdsa-sha384"},
+		{KeyTypeECDSA, 521, "ecdsa-sha512"},
+		{KeyTypeEd25519, 0, "ed25519-sha512"},
+	}
+
+	for _, c := range cases {
+		keyPair, err := GenerateKeyPair(c.keyType, c.bits, "rotated")
+		if err != nil {
+			t.Fatalf("%s: error generating key pair: %s", c.keyType, err)
+		}
+		if !strings.HasSuffix(keyPair.PublicKey, " rotated") {
+			t.Fatalf("%s: expected comment in public key %q", c.keyType, keyPair.PublicKey)
+		}
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(keyPair.PublicKey))
+		if err != nil {
+			t.Fatalf("%s: error parsing public key: %s", c.keyType, err)
+		}
+
+		signer, err := NewPrivateKeySigner(keyPair.Fingerprint, keyPair.PrivateKeyMaterial, "acct")
+		if err != nil {
+			t.Fatalf("%s: error creating signer: %s", c.keyType, err)
+		}
+		if signer.DefaultAlgorithm() != c.algorithm {
+			t.Fatalf("%s: expected algorithm %s, got %s", c.keyType, c.algorithm, signer.DefaultAlgorithm())
+		}
+
+		signature, algorithm, err := signer.SignRaw("date: Thu, 05 Jan 2017 21:31:40 GMT")
+		if err != nil {
+			t.Fatalf("%s: error signing: %s", c.keyType, err)
+		}
+		if err := Verify(publicKey, algorithm, "date: Thu, 05 Jan 2017 21:31:40 GMT", signature); err != nil {
+			t.Fatalf("%s: error verifying signature: %s", c.keyType, err)
+		}
+
+		header, err := signer.Sign("Thu, 05 Jan 2017 21:31:40 GMT")
+		if err != nil {
+			t.Fatalf("%s: error signing date header: %s", c.keyType, err)
+		}
+		keyID := "/acct/keys/" + keyPair.Fingerprint
+		if !strings.Contains(header, `keyId="`+keyID+`"`) || !strings.Contains(header, `algorithm="`+c.algorithm+`"`) {
+			t.Fatalf("%s: unexpected authorization header %q", c.keyType, header)
+		}
+	}
+}
+
+func TestGenerateKeyPair_Errors(t *testing.T) {
+	if _, err := GenerateKeyPair("dsa", 0, ""); err == nil {
+		t.Fatal("expected error for unsupported key type")
+	}
+	if _, err := GenerateKeyPair(KeyTypeECDSA, 512, ""); err == nil {
+		t.Fatal("expected error for unsupported curve size")
+	}
+}
@@ -2,9 +2,11 @@ package authentication
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"errors"
@@ -15,14 +17,15 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
+// PrivateKeySigner signs requests with an RSA, ECDSA or Ed25519 private key
+// loaded from PEM-encoded key material.
 type PrivateKeySigner struct {
 	formattedKeyFingerprint string
 	keyFingerprint          string
 	algorithm               string
 	accountName             string
-	hashFunc                crypto.Hash
 
-	privateKey *rsa.PrivateKey
+	privateKey crypto.Signer
 }
 
 func NewPrivateKeySigner(keyFingerprint string, privateKeyMaterial []byte, accountName string) (*PrivateKeySigner, error) {
@@ -33,12 +36,26 @@ func NewPrivateKeySigner(keyFingerprint string, privateKeyMaterial []byte, accou
 		return nil, errors.New("Error PEM-decoding private key material: nil block received")
 	}
 
-	rsakey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	rawKey, err := ssh.ParseRawPrivateKey(privateKeyMaterial)
 	if err != nil {
 		return nil, errwrap.Wrapf("Error parsing private key: {{err}}", err)
 	}
 
-	sshPublicKey, err := ssh.NewPublicKey(rsakey.Public())
+	var privateKey crypto.Signer
+	switch key := rawKey.(type) {
+	case *rsa.PrivateKey:
+		privateKey = key
+	case *ecdsa.PrivateKey:
+		privateKey = key
+	case ed25519.PrivateKey:
+		privateKey = key
+	case *ed25519.PrivateKey:
+		privateKey = *key
+	default:
+		return nil, fmt.Errorf("Unsupported private key type: %T", rawKey)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(privateKey.Public())
 	if err != nil {
 		return nil, errwrap.Wrapf("Error parsing SSH key from private key: {{err}}", err)
 	}
@@ -54,13 +71,12 @@ func NewPrivateKeySigner(keyFingerprint string, privateKeyMaterial []byte, accou
 		keyFingerprint:          keyFingerprint,
 		accountName:             accountName,
 
-		hashFunc:   crypto.SHA1,
-		privateKey: rsakey,
+		privateKey: privateKey,
 	}
 
 	_, algorithm, err := signer.SignRaw("HelloWorld")
 	if err != nil {
-		return nil, fmt.Errorf("Cannot sign using ssh agent: %s", err)
+		return nil, fmt.Errorf("Cannot sign using private key: %s", err)
 	}
 	signer.algorithm = algorithm
 
@@ -70,31 +86,68 @@ func NewPrivateKeySigner(keyFingerprint string, privateKeyMaterial []byte, accou
 func (s *PrivateKeySigner) Sign(dateHeader string) (string, error) {
 	const headerName = "date"
 
-	hash := s.hashFunc.New()
-	hash.Write([]byte(fmt.Sprintf("%s: %s", headerName, dateHeader)))
-	digest := hash.Sum(nil)
-
-	signed, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, s.hashFunc, digest)
+	signature, algorithm, err := s.SignRaw(fmt.Sprintf("%s: %s", headerName, dateHeader))
 	if err != nil {
 		return "", errwrap.Wrapf("Error signing date header: {{err}}", err)
 	}
-	signedBase64 := base64.StdEncoding.EncodeToString(signed)
 
-	keyID := fmt.Sprintf("/%s/keys/%s", s.accountName, s.formattedKeyFingerprint)
-	return fmt.Sprintf(authorizationHeaderFormat, keyID, "rsa-sha1", headerName, signedBase64), nil
+	keyID := KeyID(s.accountName, "", s.formattedKeyFingerprint, false)
+	return fmt.Sprintf(authorizationHeaderFormat, keyID, algorithm, headerName, signature), nil
 }
 
 func (s *PrivateKeySigner) SignRaw(toSign string) (string, string, error) {
-	hash := s.hashFunc.New()
+	switch key := s.privateKey.(type) {
+	case *rsa.PrivateKey:
+		digest := hashDigest(crypto.SHA1, toSign)
+		signed, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, digest)
+		if err != nil {
+			return "", "", errwrap.Wrapf("Error signing string: {{err}}", err)
+		}
+		return base64.StdEncoding.EncodeToString(signed), "rsa-sha1", nil
+
+	case *ecdsa.PrivateKey:
+		hashFunc, hashAlgorithm, err := ecdsaHash(key.Curve)
+		if err != nil {
+			return "", "", err
+		}
+		r, sig, err := ecdsa.Sign(rand.Reader, key, hashDigest(hashFunc, toSign))
+		if err != nil {
+			return "", "", errwrap.Wrapf("Error signing string: {{err}}", err)
+		}
+		signature := &ecdsaSignature{
+			hashAlgorithm: hashAlgorithm,
+			R:             r,
+			S:             sig,
+		}
+		return signature.String(), signature.SignatureType(), nil
+
+	case ed25519.PrivateKey:
+		signature := &ed25519Signature{signature: ed25519.Sign(key, []byte(toSign))}
+		return signature.String(), signature.SignatureType(), nil
+	}
+
+	return "", "", fmt.Errorf("Unsupported private key type: %T", s.privateKey)
+}
+
+// hashDigest returns the digest of toSign using hashFunc.
+func hashDigest(hashFunc crypto.Hash, toSign string) []byte {
+	hash := hashFunc.New()
 	hash.Write([]byte(toSign))
-	digest := hash.Sum(nil)
+	return hash.Sum(nil)
+}
 
-	signed, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, s.hashFunc, digest)
-	if err != nil {
-		return "", "", errwrap.Wrapf("Error signing date header: {{err}}", err)
+// ecdsaHash returns the hash used to sign with a key on curve, as specified
+// by RFC 5656.
+func ecdsaHash(curve elliptic.Curve) (crypto.Hash, string, error) {
+	switch curve.Params().BitSize {
+	case 256:
+		return crypto.SHA256, "sha256", nil
+	case 384:
+		return crypto.SHA384, "sha384", nil
+	case 521:
+		return crypto.SHA512, "sha512", nil
 	}
-	signedBase64 := base64.StdEncoding.EncodeToString(signed)
-	return signedBase64, "rsa-sha1", nil
+	return 0, "", fmt.Errorf("Unsupported ECDSA curve: %s", curve.Params().Name)
 }
 
 func (s *PrivateKeySigner) KeyFingerprint() string {
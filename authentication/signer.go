@@ -1,5 +1,11 @@
 package authentication
 
+import (
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+)
+
 const authorizationHeaderFormat = `Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`
 
 type Signer interface {
@@ -8,3 +14,31 @@ type Signer interface {
 	Sign(dateHeader string) (string, error)
 	SignRaw(toSign string) (string, string, error)
 }
+
+// KeyID returns the keyId of a key owned by an account or, if userName is not
+// empty, by one of its sub-users. CloudAPI and Manta name the keys of
+// sub-users differently, hence isManta.
+func KeyID(accountName, userName, keyFingerprint string, isManta bool) string {
+	if userName == "" {
+		return fmt.Sprintf("/%s/keys/%s", accountName, keyFingerprint)
+	}
+	if isManta {
+		return fmt.Sprintf("/%s/%s/keys/%s", accountName, userName, keyFingerprint)
+	}
+	return fmt.Sprintf("/%s/users/%s/keys/%s", accountName, userName, keyFingerprint)
+}
+
+// SignAsUser is like signer.Sign, but signs dateHeader with the keyId of a key
+// of the sub-user userName of accountName. The keyId is built from the
+// fingerprint of signer, so any Signer can sign as a sub-user.
+func SignAsUser(signer Signer, accountName, userName, dateHeader string, isManta bool) (string, error) {
+	const headerName = "date"
+
+	signature, algorithm, err := signer.SignRaw(fmt.Sprintf("%s: %s", headerName, dateHeader))
+	if err != nil {
+		return "", errwrap.Wrapf("Error signing date header: {{err}}", err)
+	}
+
+	keyID := KeyID(accountName, userName, signer.KeyFingerprint(), isManta)
+	return fmt.Sprintf(authorizationHeaderFormat, keyID, algorithm, headerName, signature), nil
+}
@@ -0,0 +1,64 @@
+package authentication
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestKeyID(t *testing.T) {
+	cases := []struct {
+		userName string
+		isManta  bool
+		expected string
+	}{
+		{"", false, "/acct/keys/fp"},
+		{"", true, "/acct/keys/fp"},
+		{"ci", false, "/acct/users/ci/keys/fp"},
+		{"ci", true, "/acct/ci/keys/fp"},
+	}
+
+	for _, c := range cases {
+		if keyID := KeyID("acct", c.userName, "fp", c.isManta); keyID != c.expected {
+			t.Fatalf("user %q, Manta %t: expected %q, got %q", c.userName, c.isManta, c.expected, keyID)
+		}
+	}
+}
+
+func TestSignAsUser(t *testing.T) {
+	keyPair, err := GenerateKeyPair(KeyTypeEd25519, 0, "")
+	if err != nil {
+		t.Fatalf("error generating key pair: %s", err)
+	}
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(keyPair.PublicKey))
+	if err != nil {
+		t.Fatalf("error parsing public key: %s", err)
+	}
+	signer, err := NewPrivateKeySigner(keyPair.Fingerprint, keyPair.PrivateKeyMaterial, "acct")
+	if err != nil {
+		t.Fatalf("error creating signer: %s", err)
+	}
+
+	const dateHeader = "Thu, 05 Jan 2017 21:31:40 GMT"
+	header, err := SignAsUser(signer, "acct", "ci", dateHeader, false)
+	if err != nil {
+		t.Fatalf("error signing date header: %s", err)
+	}
+	if !strings.Contains(header, `keyId="/acct/users/ci/keys/`+keyPair.Fingerprint+`"`) {
+		t.Fatalf("unexpected CloudAPI authorization header %q", header)
+	}
+
+	header, err = SignAsUser(signer, "acct", "ci", dateHeader, true)
+	if err != nil {
+		t.Fatalf("error signing date header: %s", err)
+	}
+	if !strings.Contains(header, `keyId="/acct/ci/keys/`+keyPair.Fingerprint+`"`) {
+		t.Fatalf("unexpected Manta authorization header %q", header)
+	}
+
+	signature := header[strings.Index(header, `signature="`)+len(`signature="`) : len(header)-1]
+	if err := Verify(publicKey, "ed25519-sha512", "date: "+dateHeader, signature); err != nil {
+		t.Fatalf("error verifying signature: %s", err)
+	}
+}
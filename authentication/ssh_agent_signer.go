@@ -104,6 +104,11 @@ func (s *SSHAgentSigner) Sign(dateHeader string) (string, error) {
 		if err != nil {
 			return "", errwrap.Wrapf("Error reading signature: {{err}}", err)
 		}
+	case "ed25519":
+		authSignature, err = newED25519Signature(signature.Blob)
+		if err != nil {
+			return "", errwrap.Wrapf("Error reading signature: {{err}}", err)
+		}
 	default:
 		return "", fmt.Errorf("Unsupported algorithm from SSH agent: %s", signature.Format)
 	}
@@ -135,6 +140,11 @@ func (s *SSHAgentSigner) SignRaw(toSign string) (string, string, error) {
 		if err != nil {
 			return "", "", errwrap.Wrapf("Error reading signature: {{err}}", err)
 		}
+	case "ed25519":
+		authSignature, err = newED25519Signature(signature.Blob)
+		if err != nil {
+			return "", "", errwrap.Wrapf("Error reading signature: {{err}}", err)
+		}
 	default:
 		return "", "", fmt.Errorf("Unsupported algorithm from SSH agent: %s", signature.Format)
 	}
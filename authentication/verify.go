@@ -0,0 +1,91 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"golang.org/x/crypto/ssh"
+)
+
+// Verify checks that signature is a valid signature of toVerify made with the
+// private half of publicKey. The algorithm and signature take the same form as
+// the values returned by Signer.SignRaw, e.g. "rsa-sha1" and a base64-encoded
+// signature. A nil error indicates the signature is valid.
+func Verify(publicKey ssh.PublicKey, algorithm, toVerify, signature string) error {
+	cryptoKey, ok := publicKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return fmt.Errorf("Unsupported public key type: %s", publicKey.Type())
+	}
+
+	parts := strings.SplitN(strings.ToLower(algorithm), "-", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("Invalid signature algorithm: %s", algorithm)
+	}
+
+	var hashFunc crypto.Hash
+	switch parts[1] {
+	case "sha1":
+		hashFunc = crypto.SHA1
+	case "sha256":
+		hashFunc = crypto.SHA256
+	case "sha384":
+		hashFunc = crypto.SHA384
+	case "sha512":
+		hashFunc = crypto.SHA512
+	default:
+		return fmt.Errorf("Unsupported hash algorithm: %s", parts[1])
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errwrap.Wrapf("Error decoding signature: {{err}}", err)
+	}
+
+	hash := hashFunc.New()
+	hash.Write([]byte(toVerify))
+	digest := hash.Sum(nil)
+
+	switch key := cryptoKey.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		if parts[0] != "rsa" {
+			return fmt.Errorf("Signature algorithm %s does not match RSA key", algorithm)
+		}
+		if err := rsa.VerifyPKCS1v15(key, hashFunc, digest, signatureBytes); err != nil {
+			return errwrap.Wrapf("Error verifying signature: {{err}}", err)
+		}
+	case *ecdsa.PublicKey:
+		if parts[0] != "ecdsa" {
+			return fmt.Errorf("Signature algorithm %s does not match ECDSA key", algorithm)
+		}
+		var ecSig struct {
+			R *big.Int
+			S *big.Int
+		}
+		if _, err := asn1.Unmarshal(signatureBytes, &ecSig); err != nil {
+			return errwrap.Wrapf("Error unmarshaling signature: {{err}}", err)
+		}
+		if !ecdsa.Verify(key, digest, ecSig.R, ecSig.S) {
+			return errors.New("Error verifying signature: ECDSA verification failure")
+		}
+	case ed25519.PublicKey:
+		if parts[0] != "ed25519" {
+			return fmt.Errorf("Signature algorithm %s does not match Ed25519 key", algorithm)
+		}
+		if !ed25519.Verify(key, []byte(toVerify), signatureBytes) {
+			return errors.New("Error verifying signature: Ed25519 verification failure")
+		}
+	default:
+		return fmt.Errorf("Unsupported public key type: %s", publicKey.Type())
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/errwrap"
@@ -29,6 +30,14 @@ type Client struct {
 	MantaURL    url.URL
 	AccountName string
 	Endpoint    string
+
+	// Username is the sub-user of AccountName the client signs requests as,
+	// if any.
+	Username string
+
+	// AsRole is a comma-separated list of roles which requests are made
+	// under, unless overridden for a single request with WithAsRole.
+	AsRole string
 }
 
 // New is used to construct a Client in order to make API
@@ -37,6 +46,13 @@ type Client struct {
 // At least one signer must be provided - example signers include
 // authentication.PrivateKeySigner and authentication.SSHAgentSigner.
 func New(tritonURL string, mantaURL string, accountName string, signers ...authentication.Signer) (*Client, error) {
+	return NewForUser(tritonURL, mantaURL, accountName, "", signers...)
+}
+
+// NewForUser is like New, but makes requests as the sub-user userName of the
+// account. The signers given must sign with a key of that user; the user is
+// added to the keyId of every request by the client.
+func NewForUser(tritonURL string, mantaURL string, accountName string, userName string, signers ...authentication.Signer) (*Client, error) {
 	cloudURL, err := url.Parse(tritonURL)
 	if err != nil {
 		return nil, errwrap.Wrapf("invalid endpoint URL: {{err}}", err)
@@ -62,6 +78,7 @@ func New(tritonURL string, mantaURL string, accountName string, signers ...authe
 		TritonURL:   *cloudURL,
 		MantaURL:    *storageURL,
 		AccountName: accountName,
+		Username:    userName,
 		// TODO(justinwr): Deprecated?
 		// Endpoint:    tritonURL,
 	}
@@ -104,6 +121,67 @@ func (c *Client) InsecureSkipTLSVerify() {
 	c.HTTPClient.Transport = httpTransport(true)
 }
 
+// sign returns the Authorization header of a request dated dateHeader. The
+// keyId of requests made as a sub-user is built here rather than by the
+// signer, so that Username is honoured whichever Signer is used.
+func (c *Client) sign(dateHeader string, isManta bool) (string, error) {
+	// NewClient ensures there's always an authorizer (unless this is called
+	// outside that constructor).
+	signer := c.Authorizers[0]
+	if c.Username == "" {
+		return signer.Sign(dateHeader)
+	}
+	return authentication.SignAsUser(signer, c.AccountName, c.Username, dateHeader, isManta)
+}
+
+type asRoleContextKey struct{}
+
+// WithAsRole returns a copy of ctx under which requests are made as the given
+// roles rather than Client.AsRole. Passing no roles makes requests under the
+// default roles of the user.
+func WithAsRole(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, asRoleContextKey{}, strings.Join(roles, ","))
+}
+
+// asRole returns the roles a request made with ctx is made under.
+func (c *Client) asRole(ctx context.Context) string {
+	if ctx != nil {
+		if roles, ok := ctx.Value(asRoleContextKey{}).(string); ok {
+			return roles
+		}
+	}
+	return c.AsRole
+}
+
+// tritonQuery returns the query string of a CloudAPI request, which carries
+// the roles the request is made under.
+func (c *Client) tritonQuery(ctx context.Context, query *url.Values) string {
+	roles := c.asRole(ctx)
+	if roles == "" {
+		if query == nil {
+			return ""
+		}
+		return query.Encode()
+	}
+
+	values := url.Values{}
+	if query != nil {
+		for key, value := range *query {
+			values[key] = append([]string(nil), value...)
+		}
+	}
+	values.Set("as-role", roles)
+	return values.Encode()
+}
+
+// setStorageRole sets the Role header of a Manta request, which carries the
+// roles the request is made under, unless the caller has already set it.
+func (c *Client) setStorageRole(ctx context.Context, req *http.Request) {
+	if roles := c.asRole(ctx); roles != "" && req.Header.Get("Role") == "" {
+		req.Header.Set("Role", roles)
+	}
+}
+
 func httpTransport(insecureSkipTLSVerify bool) *http.Transport {
 	return &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -169,9 +247,7 @@ func (c *Client) ExecuteRequestURIParams(ctx context.Context, inputs RequestInpu
 
 	endpoint := c.TritonURL
 	endpoint.Path = path
-	if query != nil {
-		endpoint.RawQuery = query.Encode()
-	}
+	endpoint.RawQuery = c.tritonQuery(ctx, query)
 
 	req, err := http.NewRequest(method, endpoint.String(), requestBody)
 	if err != nil {
@@ -181,9 +257,7 @@ func (c *Client) ExecuteRequestURIParams(ctx context.Context, inputs RequestInpu
 	dateHeader := time.Now().UTC().Format(time.RFC1123)
 	req.Header.Set("date", dateHeader)
 
-	// NewClient ensures there's always an authorizer (unless this is called
-	// outside that constructor).
-	authHeader, err := c.Authorizers[0].Sign(dateHeader)
+	authHeader, err := c.sign(dateHeader, false)
 	if err != nil {
 		return nil, errwrap.Wrapf("Error signing HTTP request: {{err}}", err)
 	}
@@ -216,6 +290,7 @@ func (c *Client) ExecuteRequestRaw(ctx context.Context, inputs RequestInput) (*h
 	method := inputs.Method
 	path := inputs.Path
 	body := inputs.Body
+	query := inputs.Query
 
 	var requestBody io.ReadSeeker
 	if body != nil {
@@ -228,6 +303,7 @@ func (c *Client) ExecuteRequestRaw(ctx context.Context, inputs RequestInput) (*h
 
 	endpoint := c.TritonURL
 	endpoint.Path = path
+	endpoint.RawQuery = c.tritonQuery(ctx, query)
 
 	req, err := http.NewRequest(method, endpoint.String(), requestBody)
 	if err != nil {
@@ -237,9 +313,7 @@ func (c *Client) ExecuteRequestRaw(ctx context.Context, inputs RequestInput) (*h
 	dateHeader := time.Now().UTC().Format(time.RFC1123)
 	req.Header.Set("date", dateHeader)
 
-	// NewClient ensures there's always an authorizer (unless this is called
-	// outside that constructor).
-	authHeader, err := c.Authorizers[0].Sign(dateHeader)
+	authHeader, err := c.sign(dateHeader, false)
 	if err != nil {
 		return nil, errwrap.Wrapf("Error signing HTTP request: {{err}}", err)
 	}
@@ -295,10 +369,12 @@ func (c *Client) ExecuteRequestStorage(ctx context.Context, inputs RequestInput)
 		}
 	}
 
+	c.setStorageRole(ctx, req)
+
 	dateHeader := time.Now().UTC().Format(time.RFC1123)
 	req.Header.Set("date", dateHeader)
 
-	authHeader, err := c.Authorizers[0].Sign(dateHeader)
+	authHeader, err := c.sign(dateHeader, true)
 	if err != nil {
 		return nil, nil, errwrap.Wrapf("Error signing HTTP request: {{err}}", err)
 	}
@@ -361,10 +437,12 @@ func (c *Client) ExecuteRequestNoEncode(ctx context.Context, inputs RequestNoEnc
 		}
 	}
 
+	c.setStorageRole(ctx, req)
+
 	dateHeader := time.Now().UTC().Format(time.RFC1123)
 	req.Header.Set("date", dateHeader)
 
-	authHeader, err := c.Authorizers[0].Sign(dateHeader)
+	authHeader, err := c.sign(dateHeader, true)
 	if err != nil {
 		return nil, nil, errwrap.Wrapf("Error signing HTTP request: {{err}}", err)
 	}
@@ -0,0 +1,112 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/joyent/triton-go/client"
+	"github.com/joyent/triton-go/testutils/testclient"
+)
+
+// testUserClient returns a client of the sub-user "ci" of the account "acct",
+// acting as the role "deployers", whose requests are served by handler, along
+// with the fingerprint of its key.
+func testUserClient(t *testing.T, handler http.HandlerFunc) (*client.Client, string) {
+	c := testclient.New(t, handler)
+	c.Username = "ci"
+	c.AsRole = "deployers"
+	return c, c.Authorizers[0].KeyFingerprint()
+}
+
+func TestClient_SubUserCloudAPI(t *testing.T) {
+	var req *http.Request
+	c, fingerprint := testUserClient(t, func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		w.Write([]byte("{}"))
+	})
+
+	query := &url.Values{}
+	query.Set("name", "web0")
+	respReader, err := c.ExecuteRequest(context.Background(), client.RequestInput{
+		Method: http.MethodGet,
+		Path:   "/acct/machines",
+		Query:  query,
+	})
+	if err != nil {
+		t.Fatalf("error executing request: %s", err)
+	}
+	respReader.Close()
+
+	if req.URL.Query().Get("as-role") != "deployers" || req.URL.Query().Get("name") != "web0" {
+		t.Fatalf("unexpected query %q", req.URL.RawQuery)
+	}
+	if req.Header.Get("Role") != "" {
+		t.Fatalf("unexpected Role header %q on CloudAPI request", req.Header.Get("Role"))
+	}
+	if query.Get("as-role") != "" {
+		t.Fatal("expected the query of the input to be left unchanged")
+	}
+	keyID := `keyId="/acct/users/ci/keys/` + fingerprint + `"`
+	if !strings.Contains(req.Header.Get("Authorization"), keyID) {
+		t.Fatalf("unexpected Authorization header %q", req.Header.Get("Authorization"))
+	}
+
+	respReader, err = c.ExecuteRequest(client.WithAsRole(context.Background(), "operators", "auditors"), client.RequestInput{
+		Method: http.MethodGet,
+		Path:   "/acct/machines",
+	})
+	if err != nil {
+		t.Fatalf("error executing request: %s", err)
+	}
+	respReader.Close()
+
+	if req.URL.Query().Get("as-role") != "operators,auditors" {
+		t.Fatalf("unexpected query %q", req.URL.RawQuery)
+	}
+}
+
+func TestClient_SubUserManta(t *testing.T) {
+	var req *http.Request
+	c, fingerprint := testUserClient(t, func(w http.ResponseWriter, r *http.Request) {
+		req = r
+	})
+
+	respReader, _, err := c.ExecuteRequestStorage(context.Background(), client.RequestInput{
+		Method: http.MethodGet,
+		Path:   "/acct/stor",
+	})
+	if err != nil {
+		t.Fatalf("error executing request: %s", err)
+	}
+	respReader.Close()
+
+	if req.Header.Get("Role") != "deployers" {
+		t.Fatalf("unexpected Role header %q", req.Header.Get("Role"))
+	}
+	if req.URL.Query().Get("as-role") != "" {
+		t.Fatalf("unexpected query %q on Manta request", req.URL.RawQuery)
+	}
+	keyID := `keyId="/acct/ci/keys/` + fingerprint + `"`
+	if !strings.Contains(req.Header.Get("Authorization"), keyID) {
+		t.Fatalf("unexpected Authorization header %q", req.Header.Get("Authorization"))
+	}
+
+	headers := &http.Header{}
+	headers.Set("Role", "readers")
+	respReader, _, err = c.ExecuteRequestStorage(context.Background(), client.RequestInput{
+		Method:  http.MethodGet,
+		Path:    "/acct/stor",
+		Headers: headers,
+	})
+	if err != nil {
+		t.Fatalf("error executing request: %s", err)
+	}
+	respReader.Close()
+
+	if req.Header.Get("Role") != "readers" {
+		t.Fatalf("expected explicit Role header to be kept, got %q", req.Header.Get("Role"))
+	}
+}
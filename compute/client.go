@@ -19,10 +19,11 @@ func newComputeClient(client *client.Client) *ComputeClient {
 // resources within CloudAPI
 func NewClient(config *triton.ClientConfig) (*ComputeClient, error) {
 	// TODO: Utilize config interface within the function itself
-	client, err := client.New(config.TritonURL, config.MantaURL, config.AccountName, config.Signers...)
+	client, err := client.NewForUser(config.TritonURL, config.MantaURL, config.AccountName, config.Username, config.Signers...)
 	if err != nil {
 		return nil, err
 	}
+	client.AsRole = config.AsRole
 	return newComputeClient(client), nil
 }
 
@@ -0,0 +1,115 @@
+package compute
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Zones which CNS publishes names in. The public zone only resolves to the
+// public IPs of instances, the private zone to every IP.
+const (
+	CNSZonePublic  = "public"
+	CNSZonePrivate = "private"
+)
+
+// Suffixes of the CNS zones of the Joyent public cloud, used when
+// CNSNamesInput.Suffix is not set.
+const (
+	CNSPublicSuffix  = "triton.zone"
+	CNSPrivateSuffix = "cns.joyent.com"
+)
+
+var cnsLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// CNSNames are the DNS names CNS gives an instance in one zone.
+type CNSNames struct {
+	// Instance are the names of the instance itself, by ID and, if its name
+	// is a valid DNS label, by name.
+	Instance []string
+
+	// Services are the names of the CNS services the instance belongs to,
+	// which resolve to every instance of the service.
+	Services []string
+}
+
+// CNSNamesInput represents parameters to CNSNamesFor.
+type CNSNamesInput struct {
+	// AccountID is the UUID of the account owning the instance. Required.
+	AccountID string
+
+	// InstanceID is the UUID of the instance. Required.
+	InstanceID string
+
+	// InstanceName is the name (alias) of the instance. Optional.
+	InstanceName string
+
+	// CNS holds the CNS settings of the instance.
+	CNS InstanceCNS
+
+	// Datacenter is the name of the datacenter, e.g. "us-east-1". Required.
+	Datacenter string
+
+	// Zone is CNSZonePublic or CNSZonePrivate. Defaults to the public zone.
+	Zone string
+
+	// Suffix is the DNS suffix of the zone. Defaults to the suffix of the
+	// zone in the Joyent public cloud.
+	Suffix string
+}
+
+// CNSNamesFor computes the DNS names CNS gives an instance. Instances with
+// CNS disabled have no names. It does not check that CNS is enabled for the
+// account, since this is not known to the instance.
+func CNSNamesFor(input *CNSNamesInput) (*CNSNames, error) {
+	if input.AccountID == "" || input.InstanceID == "" || input.Datacenter == "" {
+		return nil, errors.New("AccountID, InstanceID and Datacenter are required to compute CNS names")
+	}
+
+	suffix := input.Suffix
+	switch input.Zone {
+	case "", CNSZonePublic:
+		if suffix == "" {
+			suffix = CNSPublicSuffix
+		}
+	case CNSZonePrivate:
+		if suffix == "" {
+			suffix = CNSPrivateSuffix
+		}
+	default:
+		return nil, fmt.Errorf("unknown CNS zone %q", input.Zone)
+	}
+	suffix = strings.Trim(strings.ToLower(suffix), ".")
+
+	names := &CNSNames{
+		Instance: []string{},
+		Services: []string{},
+	}
+	if input.CNS.Disable {
+		return names, nil
+	}
+
+	domain := func(label, kind string) string {
+		return fmt.Sprintf("%s.%s.%s.%s.%s", label, kind,
+			strings.ToLower(input.AccountID), strings.ToLower(input.Datacenter), suffix)
+	}
+
+	names.Instance = append(names.Instance, domain(strings.ToLower(input.InstanceID), "inst"))
+	if name := strings.ToLower(input.InstanceName); cnsLabel.MatchString(name) {
+		names.Instance = append(names.Instance, domain(name, "inst"))
+	}
+
+	seen := map[string]bool{}
+	for _, service := range input.CNS.Services {
+		// Services may declare a port and other options, e.g. "web:8080".
+		label := strings.ToLower(strings.TrimSpace(strings.SplitN(service, ":", 2)[0]))
+		if !cnsLabel.MatchString(label) || seen[label] {
+			continue
+		}
+		seen[label] = true
+		names.Services = append(names.Services, domain(label, "svc"))
+	}
+
+	return names, nil
+}
@@ -0,0 +1,176 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestTagsExtractMeta(t *testing.T) {
+	cases := []struct {
+		tags map[string]interface{}
+		cns  InstanceCNS
+	}{
+		{
+			tags: map[string]interface{}{
+				CNSTagDisable:    true,
+				CNSTagReversePTR: "web.example.com",
+				CNSTagServices:   "web:8080, api,,",
+				"role":           "web",
+			},
+			cns: InstanceCNS{
+				Disable:    true,
+				ReversePTR: "web.example.com",
+				Services:   []string{"web:8080", "api"},
+			},
+		},
+		{
+			tags: map[string]interface{}{CNSTagDisable: "true", "role": "web"},
+			cns:  InstanceCNS{Disable: true},
+		},
+		{
+			tags: map[string]interface{}{CNSTagDisable: "false", "role": "web"},
+			cns:  InstanceCNS{},
+		},
+	}
+
+	for _, c := range cases {
+		cns, tags, err := tagsExtractMeta(c.tags)
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %s", c.tags, err)
+		}
+		if !reflect.DeepEqual(cns, c.cns) {
+			t.Fatalf("expected CNS %+v, got %+v", c.cns, cns)
+		}
+		if !reflect.DeepEqual(tags, map[string]interface{}{"role": "web"}) {
+			t.Fatalf("unexpected tags %v", tags)
+		}
+	}
+}
+
+func TestTagsExtractMeta_Malformed(t *testing.T) {
+	malformed := []map[string]interface{}{
+		{CNSTagDisable: "maybe"},
+		{CNSTagDisable: 1.0},
+		{CNSTagReversePTR: false},
+		{CNSTagServices: []interface{}{"web"}},
+	}
+
+	for _, tags := range malformed {
+		if _, _, err := tagsExtractMeta(tags); err == nil {
+			t.Fatalf("expected error for %v", tags)
+		}
+	}
+
+	api := &_Instance{Tags: malformed[0]}
+	instance, err := api.toNative()
+	if err == nil {
+		t.Fatal("expected toNative to fail on a malformed CNS tag")
+	}
+	if !reflect.DeepEqual(instance.Tags, malformed[0]) || !reflect.DeepEqual(instance.CNS, InstanceCNS{}) {
+		t.Fatalf("expected instance with raw tags and empty CNS, got %+v", instance)
+	}
+}
+
+func TestAddTagsInput_toAPI(t *testing.T) {
+	input := AddTagsInput{
+		ID:   "i1",
+		Tags: map[string]string{"role": "web"},
+		CNS: InstanceCNS{
+			Disable:  true,
+			Services: []string{"web", "api"},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"role":         "web",
+		CNSTagDisable:  true,
+		CNSTagServices: "web,api",
+	}
+	if got := input.toAPI(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestList_MalformedCNSTag(t *testing.T) {
+	c := testComputeClient(t, testResponses(testResponse{http.StatusOK, `[
+		{"id": "i1", "tags": {"triton.cns.disable": "maybe"}},
+		{"id": "i2", "tags": {"triton.cns.services": "web"}}
+	]`}))
+
+	instances, err := c.Instances().List(context.Background(), &ListInstancesInput{})
+	if err != nil {
+		t.Fatalf("error listing instances: %s", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("expected 2 instances, got %d", len(instances))
+	}
+	if !reflect.DeepEqual(instances[0].CNS, InstanceCNS{}) || instances[0].Tags[CNSTagDisable] != "maybe" {
+		t.Fatalf("unexpected malformed instance %+v", instances[0])
+	}
+	if instances[0].CNSError == nil {
+		t.Fatal("expected the malformed CNS tag to be reported in CNSError")
+	}
+	if instances[1].CNSError != nil {
+		t.Fatalf("unexpected CNSError %s", instances[1].CNSError)
+	}
+	if !reflect.DeepEqual(instances[1].CNS.Services, []string{"web"}) {
+		t.Fatalf("unexpected instance %+v", instances[1])
+	}
+}
+
+func TestSetCNS(t *testing.T) {
+	var requests []string
+	var added map[string]interface{}
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"role": "web", "triton.cns.disable": true, "triton.cns.reverse_ptr": "web.example.com"}`))
+		case http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&added)
+			w.Write([]byte(`{}`))
+		}
+	})
+
+	err := c.Instances().SetCNS(context.Background(), &SetCNSInput{
+		ID:  "i1",
+		CNS: InstanceCNS{Services: []string{"web", "api"}},
+	})
+	if err != nil {
+		t.Fatalf("error setting CNS: %s", err)
+	}
+
+	// The GET and POST bracket the deletes, which may come in any order.
+	if len(requests) != 4 || requests[0] != "GET /acct/machines/i1/tags" || requests[3] != "POST /acct/machines/i1/tags" {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+	deleted := map[string]bool{requests[1]: true, requests[2]: true}
+	if !deleted["DELETE /acct/machines/i1/tags/triton.cns.disable"] || !deleted["DELETE /acct/machines/i1/tags/triton.cns.reverse_ptr"] {
+		t.Fatalf("unexpected deletes %v", requests[1:3])
+	}
+	if !reflect.DeepEqual(added, map[string]interface{}{CNSTagServices: "web,api"}) {
+		t.Fatalf("unexpected added tags %v", added)
+	}
+}
+
+func TestSetCNS_Clear(t *testing.T) {
+	var requests []string
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"role": "web", "triton.cns.services": "web"}`))
+		}
+	})
+
+	if err := c.Instances().SetCNS(context.Background(), &SetCNSInput{ID: "i1"}); err != nil {
+		t.Fatalf("error clearing CNS: %s", err)
+	}
+
+	expected := []string{"GET /acct/machines/i1/tags", "DELETE /acct/machines/i1/tags/triton.cns.services"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+}
@@ -0,0 +1,69 @@
+package compute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCNSNamesFor(t *testing.T) {
+	input := &CNSNamesInput{
+		AccountID:    "4d649f41-cf87-ca1d-c2c0-bb6a9004311d",
+		InstanceID:   "A0E46AD0-96B7-4F5A-A1D4-2A36E7AD4FFB",
+		InstanceName: "Web0",
+		CNS: InstanceCNS{
+			Services: []string{"web:8080", "api", "web", "not_valid"},
+		},
+		Datacenter: "us-east-1",
+	}
+
+	names, err := CNSNamesFor(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := &CNSNames{
+		Instance: []string{
+			"a0e46ad0-96b7-4f5a-a1d4-2a36e7ad4ffb.inst.4d649f41-cf87-ca1d-c2c0-bb6a9004311d.us-east-1.triton.zone",
+			"web0.inst.4d649f41-cf87-ca1d-c2c0-bb6a9004311d.us-east-1.triton.zone",
+		},
+		Services: []string{
+			"web.svc.4d649f41-cf87-ca1d-c2c0-bb6a9004311d.us-east-1.triton.zone",
+			"api.svc.4d649f41-cf87-ca1d-c2c0-bb6a9004311d.us-east-1.triton.zone",
+		},
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected names: %+v", names)
+	}
+
+	input.Zone = CNSZonePrivate
+	input.InstanceName = "web_0"
+	input.CNS.Services = nil
+	names, err = CNSNamesFor(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(names.Instance) != 1 || len(names.Services) != 0 ||
+		names.Instance[0] != "a0e46ad0-96b7-4f5a-a1d4-2a36e7ad4ffb.inst.4d649f41-cf87-ca1d-c2c0-bb6a9004311d.us-east-1.cns.joyent.com" {
+		t.Fatalf("unexpected private names: %+v", names)
+	}
+
+	input.Suffix = "cns.example.com."
+	names, _ = CNSNamesFor(input)
+	if names.Instance[0] != "a0e46ad0-96b7-4f5a-a1d4-2a36e7ad4ffb.inst.4d649f41-cf87-ca1d-c2c0-bb6a9004311d.us-east-1.cns.example.com" {
+		t.Fatalf("unexpected custom suffix name: %s", names.Instance[0])
+	}
+
+	input.CNS.Disable = true
+	names, _ = CNSNamesFor(input)
+	if len(names.Instance) != 0 {
+		t.Fatalf("expected no names with CNS disabled, got %+v", names)
+	}
+}
+
+func TestCNSNamesFor_Errors(t *testing.T) {
+	if _, err := CNSNamesFor(&CNSNamesInput{InstanceID: "i", Datacenter: "dc"}); err == nil {
+		t.Fatal("expected error without AccountID")
+	}
+	if _, err := CNSNamesFor(&CNSNamesInput{AccountID: "a", InstanceID: "i", Datacenter: "dc", Zone: "internal"}); err == nil {
+		t.Fatal("expected error for unknown zone")
+	}
+}
@@ -2,8 +2,10 @@ package compute
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
 )
 
 // TritonError represents an error code and message along with
@@ -104,6 +106,29 @@ func IsResourceNotFound(err error) bool {
 	return isSpecificError(err, "ResourceNotFound")
 }
 
+// isNotFound tests whether err wraps a TritonError with code
+// ResourceNotFound, or a client.ClientError for a resource which does not
+// exist. Requests which check the response status, such as ExecuteRequest,
+// fail with the latter.
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsResourceNotFound(err) {
+		return true
+	}
+
+	clientErrorInterface := errwrap.GetType(err, &client.ClientError{})
+	if clientErrorInterface == nil {
+		return false
+	}
+
+	clientErr := clientErrorInterface.(*client.ClientError)
+	return clientErr.Code == "ResourceNotFound" ||
+		clientErr.StatusCode == http.StatusNotFound ||
+		clientErr.StatusCode == http.StatusGone
+}
+
 // IsUnknownError tests whether err wraps a TritonError with
 // code UnknownError
 func IsUnknownError(err error) bool {
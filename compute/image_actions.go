@@ -0,0 +1,285 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// States of an image.
+const (
+	ImageStateActive      = "active"
+	ImageStateUnactivated = "unactivated"
+	ImageStateDisabled    = "disabled"
+	ImageStateCreating    = "creating"
+	ImageStateFailed      = "failed"
+)
+
+const (
+	defaultImagePollInterval        = 5 * time.Second
+	defaultImageNotFoundGracePeriod = time.Minute
+)
+
+type ImportImageInput struct {
+	// ImageID is the ID of the image in the source datacenter.
+	ImageID string
+
+	// Datacenter is the name of the datacenter to import the image from.
+	Datacenter string
+}
+
+// ImportFromDatacenter copies an image owned by the account from another
+// datacenter of the same cloud into this one, keeping its ID. The import runs
+// asynchronously; use WaitForState to wait for the image to become active.
+func (c *ImagesClient) ImportFromDatacenter(ctx context.Context, input *ImportImageInput) (*Image, error) {
+	path := fmt.Sprintf("/%s/images", c.client.AccountName)
+	query := &url.Values{}
+	query.Set("action", "import-from-datacenter")
+	query.Set("datacenter", input.Datacenter)
+	query.Set("id", input.ImageID)
+
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Query:  query,
+	}
+	respReader, err := c.client.ExecuteRequestURIParams(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ImportFromDatacenter request: {{err}}", err)
+	}
+
+	var result *Image
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ImportFromDatacenter response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type CloneImageInput struct {
+	ImageID string
+}
+
+// Clone copies an image shared with the account into an image owned by the
+// account, with a new ID.
+func (c *ImagesClient) Clone(ctx context.Context, input *CloneImageInput) (*Image, error) {
+	path := fmt.Sprintf("/%s/images/%s", c.client.AccountName, input.ImageID)
+	query := &url.Values{}
+	query.Set("action", "clone")
+
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Query:  query,
+	}
+	respReader, err := c.client.ExecuteRequestURIParams(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing Clone request: {{err}}", err)
+	}
+
+	var result *Image
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding Clone response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// SharedWith reports whether the image is shared with an account through its
+// ACL.
+func (i *Image) SharedWith(accountID string) bool {
+	for _, id := range i.ACL {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// ACLWith returns the ACL of the image with accountIDs added, without
+// duplicates. Like ACLWithout, it does not change the image; use Share to
+// change its ACL.
+func (i *Image) ACLWith(accountIDs ...string) []string {
+	acl := append([]string{}, i.ACL...)
+	for _, id := range accountIDs {
+		shared := false
+		for _, existing := range acl {
+			if existing == id {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			acl = append(acl, id)
+		}
+	}
+	return acl
+}
+
+// ACLWithout returns the ACL of the image with accountIDs removed.
+func (i *Image) ACLWithout(accountIDs ...string) []string {
+	remove := make(map[string]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		remove[id] = true
+	}
+
+	acl := []string{}
+	for _, id := range i.ACL {
+		if !remove[id] {
+			acl = append(acl, id)
+		}
+	}
+	return acl
+}
+
+type ShareImageInput struct {
+	ImageID string
+
+	// AccountID is the UUID of the account to share or unshare the image
+	// with.
+	AccountID string
+}
+
+// Share adds an account to the ACL of an image, allowing it to provision
+// instances from the image.
+func (c *ImagesClient) Share(ctx context.Context, input *ShareImageInput) (*Image, error) {
+	result, err := c.aclAction(ctx, "share", input)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing Share request: {{err}}", err)
+	}
+	return result, nil
+}
+
+// Unshare removes an account from the ACL of an image.
+func (c *ImagesClient) Unshare(ctx context.Context, input *ShareImageInput) (*Image, error) {
+	result, err := c.aclAction(ctx, "unshare", input)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing Unshare request: {{err}}", err)
+	}
+	return result, nil
+}
+
+// aclAction executes the share or unshare action on an image. CloudAPI
+// changes the ACL itself, so concurrent changes to it are not lost.
+func (c *ImagesClient) aclAction(ctx context.Context, action string, input *ShareImageInput) (*Image, error) {
+	path := fmt.Sprintf("/%s/images/%s", c.client.AccountName, input.ImageID)
+	query := &url.Values{}
+	query.Set("action", action)
+	query.Set("account", input.AccountID)
+
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Query:  query,
+	}
+	respReader, err := c.client.ExecuteRequestURIParams(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result *Image
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type WaitForImageStateInput struct {
+	ImageID string
+
+	// State is the state to wait for. Defaults to "active".
+	State string
+
+	// PollInterval is the delay between checks of the image. Defaults to 5
+	// seconds.
+	PollInterval time.Duration
+
+	// NotFoundGracePeriod is how long the image may not be found after the
+	// wait starts, since an imported image may not be visible immediately.
+	// Defaults to 1 minute.
+	NotFoundGracePeriod time.Duration
+}
+
+// WaitForState polls an image until it reaches the given state, and returns
+// it. It fails as soon as the image is in the "failed" state, or if the image
+// is still not found once NotFoundGracePeriod has passed. Use a context with a
+// deadline to bound the wait.
+func (c *ImagesClient) WaitForState(ctx context.Context, input *WaitForImageStateInput) (*Image, error) {
+	state := input.State
+	if state == "" {
+		state = ImageStateActive
+	}
+	interval := input.PollInterval
+	if interval <= 0 {
+		interval = defaultImagePollInterval
+	}
+	gracePeriod := input.NotFoundGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultImageNotFoundGracePeriod
+	}
+	notFoundDeadline := time.Now().Add(gracePeriod)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// notFoundErr is the last not-found error, reported if the context ends
+	// before the image is found.
+	var notFoundErr error
+	for {
+		image, err := c.Get(ctx, &GetImageInput{ImageID: input.ImageID})
+		switch {
+		case err == nil:
+			notFoundErr = nil
+			if image.State == state {
+				return image, nil
+			}
+			if image.State == ImageStateFailed {
+				return nil, errwrap.Wrapf("Error executing WaitForImageState request: image failed: {{err}}", image.Error)
+			}
+		case isNotFound(err):
+			// An imported image may not be visible immediately.
+			if time.Now().After(notFoundDeadline) {
+				return nil, errwrap.Wrapf("Error executing WaitForImageState request: {{err}}", err)
+			}
+			notFoundErr = err
+		case ctx.Err() != nil:
+			return nil, imageWaitCancelled(ctx.Err(), notFoundErr)
+		default:
+			return nil, errwrap.Wrapf("Error executing WaitForImageState request: {{err}}", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, imageWaitCancelled(ctx.Err(), notFoundErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// imageWaitCancelled returns the error of a WaitForState call whose context
+// ended, including the last not-found error if the image was not found.
+func imageWaitCancelled(ctxErr, notFoundErr error) error {
+	if notFoundErr != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error executing WaitForImageState request: %s: {{err}}", ctxErr), notFoundErr)
+	}
+	return errwrap.Wrapf("Error executing WaitForImageState request: {{err}}", ctxErr)
+}
@@ -0,0 +1,197 @@
+package compute
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImageACL(t *testing.T) {
+	image := &Image{ACL: []string{"a1", "a2"}}
+
+	if !image.SharedWith("a1") || image.SharedWith("a3") {
+		t.Fatalf("unexpected SharedWith result for ACL %v", image.ACL)
+	}
+
+	if acl := image.ACLWith("a2", "a3", "a3"); !reflect.DeepEqual(acl, []string{"a1", "a2", "a3"}) {
+		t.Fatalf("unexpected ACL after sharing: %v", acl)
+	}
+	if acl := image.ACLWithout("a1", "a3"); !reflect.DeepEqual(acl, []string{"a2"}) {
+		t.Fatalf("unexpected ACL after unsharing: %v", acl)
+	}
+	if acl := image.ACLWithout("a1", "a2"); acl == nil || len(acl) != 0 {
+		t.Fatalf("expected an empty, non-nil ACL, got %#v", acl)
+	}
+	if !reflect.DeepEqual(image.ACL, []string{"a1", "a2"}) {
+		t.Fatalf("expected the image ACL to be left unchanged, got %v", image.ACL)
+	}
+}
+
+// testImageRequests returns a ComputeClient which records the requests made to
+// it and answers them with image.
+func testImageRequests(t *testing.T, image string) (*ComputeClient, *[]*http.Request) {
+	requests := []*http.Request{}
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if len(body) != 0 {
+			t.Errorf("unexpected request body %s", body)
+		}
+		requests = append(requests, r)
+		w.Write([]byte(image))
+	})
+	return c, &requests
+}
+
+func TestImportFromDatacenter(t *testing.T) {
+	c, requests := testImageRequests(t, `{"id": "img", "state": "unactivated"}`)
+
+	image, err := c.Images().ImportFromDatacenter(context.Background(), &ImportImageInput{
+		ImageID:    "img",
+		Datacenter: "us-west-1",
+	})
+	if err != nil {
+		t.Fatalf("error importing image: %s", err)
+	}
+	if image.ID != "img" {
+		t.Fatalf("unexpected image %+v", image)
+	}
+
+	r := (*requests)[0]
+	expected := url.Values{
+		"action":     []string{"import-from-datacenter"},
+		"datacenter": []string{"us-west-1"},
+		"id":         []string{"img"},
+	}
+	if r.Method != http.MethodPost || r.URL.Path != "/acct/images" || !reflect.DeepEqual(r.URL.Query(), expected) {
+		t.Fatalf("unexpected request %s %s", r.Method, r.URL)
+	}
+}
+
+func TestClone(t *testing.T) {
+	c, requests := testImageRequests(t, `{"id": "clone", "state": "active"}`)
+
+	image, err := c.Images().Clone(context.Background(), &CloneImageInput{ImageID: "img"})
+	if err != nil {
+		t.Fatalf("error cloning image: %s", err)
+	}
+	if image.ID != "clone" {
+		t.Fatalf("unexpected image %+v", image)
+	}
+
+	r := (*requests)[0]
+	expected := url.Values{"action": []string{"clone"}}
+	if r.Method != http.MethodPost || r.URL.Path != "/acct/images/img" || !reflect.DeepEqual(r.URL.Query(), expected) {
+		t.Fatalf("unexpected request %s %s", r.Method, r.URL)
+	}
+}
+
+func TestShareUnshare(t *testing.T) {
+	c, requests := testImageRequests(t, `{"id": "img", "acl": ["a1"]}`)
+	input := &ShareImageInput{ImageID: "img", AccountID: "a1"}
+
+	image, err := c.Images().Share(context.Background(), input)
+	if err != nil {
+		t.Fatalf("error sharing image: %s", err)
+	}
+	if !image.SharedWith("a1") {
+		t.Fatalf("unexpected image %+v", image)
+	}
+	if _, err := c.Images().Unshare(context.Background(), input); err != nil {
+		t.Fatalf("error unsharing image: %s", err)
+	}
+
+	for i, action := range []string{"share", "unshare"} {
+		r := (*requests)[i]
+		expected := url.Values{"action": []string{action}, "account": []string{"a1"}}
+		if r.Method != http.MethodPost || r.URL.Path != "/acct/images/img" || !reflect.DeepEqual(r.URL.Query(), expected) {
+			t.Fatalf("unexpected %s request %s %s", action, r.Method, r.URL)
+		}
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+}
+
+func TestWaitForState(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusNotFound, `{"code": "ResourceNotFound", "message": "image not found"}`},
+		testResponse{http.StatusOK, `{"id": "img", "state": "creating"}`},
+		testResponse{http.StatusOK, `{"id": "img", "state": "active"}`},
+	))
+
+	image, err := c.Images().WaitForState(context.Background(), &WaitForImageStateInput{
+		ImageID:      "img",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("error waiting for image: %s", err)
+	}
+	if image.State != ImageStateActive {
+		t.Fatalf("unexpected image state %q", image.State)
+	}
+}
+
+func TestWaitForState_Failed(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusNotFound, `{"code": "ResourceNotFound", "message": "image not found"}`},
+		testResponse{http.StatusOK, `{"id": "img", "state": "creating"}`},
+		testResponse{http.StatusOK, `{"id": "img", "state": "failed", "error": {"code": "ImportFailed", "message": "out of space"}}`},
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := c.Images().WaitForState(ctx, &WaitForImageStateInput{
+		ImageID:      "img",
+		PollInterval: time.Millisecond,
+	})
+	if err == nil || ctx.Err() != nil {
+		t.Fatalf("expected WaitForState to fail before the deadline, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "out of space") {
+		t.Fatalf("expected the image error to be reported, got %s", err)
+	}
+}
+
+func TestWaitForState_NotFoundGracePeriod(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusNotFound, `{"code": "ResourceNotFound", "message": "image not found"}`},
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.Images().WaitForState(ctx, &WaitForImageStateInput{
+		ImageID:             "img",
+		PollInterval:        time.Millisecond,
+		NotFoundGracePeriod: 20 * time.Millisecond,
+	})
+	if err == nil || ctx.Err() != nil {
+		t.Fatalf("expected WaitForState to fail before the deadline, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "image not found") {
+		t.Fatalf("expected the not-found error to be reported, got %s", err)
+	}
+}
+
+func TestWaitForState_NotFoundUntilDeadline(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusNotFound, `{"code": "ResourceNotFound", "message": "image not found"}`},
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.Images().WaitForState(ctx, &WaitForImageStateInput{
+		ImageID:      "img",
+		PollInterval: time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("expected WaitForState to fail")
+	}
+	if !strings.Contains(err.Error(), "image not found") || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected the deadline and the last not-found error to be reported, got %s", err)
+	}
+}
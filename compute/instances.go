@@ -3,10 +3,13 @@ package compute
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -55,6 +58,11 @@ type Instance struct {
 	Package         string                 `json:"package"`
 	DomainNames     []string               `json:"dns_names"`
 	CNS             InstanceCNS
+
+	// CNSError is set by List when the CNS tags of the instance are
+	// malformed. CNS is then empty and Tags holds every tag, including the
+	// CNS ones, as returned by CloudAPI.
+	CNSError error `json:"-"`
 }
 
 // _Instance is a private facade over Instance that handles the necessary API
@@ -193,9 +201,11 @@ func (c *InstancesClient) List(ctx context.Context, input *ListInstancesInput) (
 
 	machines := make([]*Instance, 0, len(results))
 	for _, machineAPI := range results {
+		// An instance with malformed CNS tags does not fail the whole
+		// list; the error is reported in its CNSError instead.
 		native, err := machineAPI.toNative()
 		if err != nil {
-			return nil, errwrap.Wrapf("unable to convert API response for instances to native type: {{err}}", err)
+			native.CNSError = err
 		}
 		machines = append(machines, native)
 	}
@@ -203,6 +213,35 @@ func (c *InstancesClient) List(ctx context.Context, input *ListInstancesInput) (
 	return machines, nil
 }
 
+const listAllInstancesPageSize = 1000
+
+// ListAll pages through every instance matching the filters of input, which
+// may be nil. The Limit and Offset of input are ignored.
+func (c *InstancesClient) ListAll(ctx context.Context, input *ListInstancesInput) ([]*Instance, error) {
+	pageInput := ListInstancesInput{}
+	if input != nil {
+		pageInput = *input
+	}
+	pageInput.Limit = listAllInstancesPageSize
+
+	var result []*Instance
+	for offset := 0; ; offset += listAllInstancesPageSize {
+		if offset > math.MaxUint16 {
+			return nil, errors.New("Error executing ListAll request: too many instances to page through")
+		}
+		pageInput.Offset = uint16(offset)
+
+		page, err := c.List(ctx, &pageInput)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error executing ListAll request: {{err}}", err)
+		}
+		result = append(result, page...)
+		if len(page) < listAllInstancesPageSize {
+			return result, nil
+		}
+	}
+}
+
 type CreateInstanceInput struct {
 	Name            string
 	Package         string
@@ -435,6 +474,21 @@ func (c *InstancesClient) ReplaceTags(ctx context.Context, input *ReplaceTagsInp
 type AddTagsInput struct {
 	ID   string
 	Tags map[string]string
+
+	// CNS adds the CNS tags which are set. Use SetCNS to also remove CNS
+	// tags.
+	CNS InstanceCNS
+}
+
+// toAPI is used to join Tags and CNS tags into the same JSON object before
+// sending an API request to the API gateway.
+func (input AddTagsInput) toAPI() map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range input.Tags {
+		result[key] = value
+	}
+	input.CNS.toTags(result)
+	return result
 }
 
 func (c *InstancesClient) AddTags(ctx context.Context, input *AddTagsInput) error {
@@ -442,7 +496,7 @@ func (c *InstancesClient) AddTags(ctx context.Context, input *AddTagsInput) erro
 	reqInputs := client.RequestInput{
 		Method: http.MethodPost,
 		Path:   path,
-		Body:   input.Tags,
+		Body:   input.toAPI(),
 	}
 	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
 	if respReader != nil {
@@ -488,7 +542,22 @@ type ListTagsInput struct {
 }
 
 func (c *InstancesClient) ListTags(ctx context.Context, input *ListTagsInput) (map[string]interface{}, error) {
-	path := fmt.Sprintf("/%s/machines/%s/tags", c.client.AccountName, input.ID)
+	result, err := c.listTagsAPI(ctx, input.ID)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListTags request: {{err}}", err)
+	}
+
+	_, tags, err := tagsExtractMeta(result)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error decoding ListTags response: {{err}}", err)
+	}
+	return tags, nil
+}
+
+// listTagsAPI returns every tag of an instance, including CNS tags, as
+// returned by the API.
+func (c *InstancesClient) listTagsAPI(ctx context.Context, id string) (map[string]interface{}, error) {
+	path := fmt.Sprintf("/%s/machines/%s/tags", c.client.AccountName, id)
 	reqInputs := client.RequestInput{
 		Method: http.MethodGet,
 		Path:   path,
@@ -498,17 +567,69 @@ func (c *InstancesClient) ListTags(ctx context.Context, input *ListTagsInput) (m
 		defer respReader.Close()
 	}
 	if err != nil {
-		return nil, errwrap.Wrapf("Error executing ListTags request: {{err}}", err)
+		return nil, err
 	}
 
 	var result map[string]interface{}
 	decoder := json.NewDecoder(respReader)
 	if err = decoder.Decode(&result); err != nil {
-		return nil, errwrap.Wrapf("Error decoding ListTags response: {{err}}", err)
+		return nil, err
 	}
 
-	_, tags := tagsExtractMeta(result)
-	return tags, nil
+	return result, nil
+}
+
+type GetCNSInput struct {
+	ID string
+}
+
+// GetCNS returns the CNS settings of an instance, parsed from its CNS tags.
+func (c *InstancesClient) GetCNS(ctx context.Context, input *GetCNSInput) (*InstanceCNS, error) {
+	result, err := c.listTagsAPI(ctx, input.ID)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetCNS request: {{err}}", err)
+	}
+
+	cns, _, err := tagsExtractMeta(result)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error decoding GetCNS response: {{err}}", err)
+	}
+	return &cns, nil
+}
+
+type SetCNSInput struct {
+	ID  string
+	CNS InstanceCNS
+}
+
+// SetCNS replaces the CNS settings of an instance, leaving its other tags
+// untouched. CNS tags of settings which are not set are removed.
+func (c *InstancesClient) SetCNS(ctx context.Context, input *SetCNSInput) error {
+	current, err := c.listTagsAPI(ctx, input.ID)
+	if err != nil {
+		return errwrap.Wrapf("Error executing SetCNS request: {{err}}", err)
+	}
+
+	desired := map[string]interface{}{}
+	input.CNS.toTags(desired)
+
+	for key := range reservedInstanceCNSTags {
+		_, isSet := desired[key]
+		if _, exists := current[key]; exists && !isSet {
+			err := c.DeleteTag(ctx, &DeleteTagInput{ID: input.ID, Key: key})
+			if err != nil {
+				return errwrap.Wrapf("Error executing SetCNS request: {{err}}", err)
+			}
+		}
+	}
+
+	if len(desired) == 0 {
+		return nil
+	}
+	if err := c.AddTags(ctx, &AddTagsInput{ID: input.ID, CNS: input.CNS}); err != nil {
+		return errwrap.Wrapf("Error executing SetCNS request: {{err}}", err)
+	}
+	return nil
 }
 
 type GetMetadataInput struct {
@@ -787,8 +908,7 @@ func (c *InstancesClient) GetNIC(ctx context.Context, input *GetNICInput) (*NIC,
 	if response != nil {
 		defer response.Body.Close()
 	}
-	switch response.StatusCode {
-	case http.StatusNotFound:
+	if response != nil && response.StatusCode == http.StatusNotFound {
 		return nil, &TritonError{
 			StatusCode: response.StatusCode,
 			Code:       "ResourceNotFound",
@@ -810,6 +930,9 @@ func (c *InstancesClient) GetNIC(ctx context.Context, input *GetNICInput) (*NIC,
 type AddNICInput struct {
 	InstanceID string `json:"-"`
 	Network    string `json:"network"`
+
+	// Primary makes the new NIC the primary NIC of the instance. Optional.
+	Primary bool `json:"primary,omitempty"`
 }
 
 // AddNIC asynchronously adds a NIC to a given instance.  If a NIC for a given
@@ -942,37 +1065,64 @@ var reservedInstanceCNSTags = map[string]struct{}{
 }
 
 // tagsExtractMeta() extracts all of the misc parameters from Tags and returns a
-// clean CNS and Tags struct.
-func tagsExtractMeta(tags map[string]interface{}) (InstanceCNS, map[string]interface{}) {
+// clean CNS and Tags struct. CNS tags may be given in their native type or as
+// strings; any other type is an error.
+func tagsExtractMeta(tags map[string]interface{}) (InstanceCNS, map[string]interface{}, error) {
 	nativeCNS := InstanceCNS{}
 	nativeTags := make(map[string]interface{}, len(tags))
 	for k, raw := range tags {
-		if _, found := reservedInstanceCNSTags[k]; found {
-			switch k {
-			case CNSTagDisable:
-				b := raw.(bool)
+		if _, found := reservedInstanceCNSTags[k]; !found {
+			nativeTags[k] = raw
+			continue
+		}
+
+		switch k {
+		case CNSTagDisable:
+			switch v := raw.(type) {
+			case bool:
+				nativeCNS.Disable = v
+			case string:
+				b, err := strconv.ParseBool(strings.TrimSpace(v))
+				if err != nil {
+					return InstanceCNS{}, nil, fmt.Errorf("invalid %s tag value %q", k, v)
+				}
 				nativeCNS.Disable = b
-			case CNSTagReversePTR:
-				s := raw.(string)
-				nativeCNS.ReversePTR = s
-			case CNSTagServices:
-				nativeCNS.Services = strings.Split(raw.(string), ",")
 			default:
-				// TODO(seanc@): should assert, logic fail
+				return InstanceCNS{}, nil, fmt.Errorf("invalid %s tag value of type %T", k, raw)
+			}
+		case CNSTagReversePTR:
+			v, ok := raw.(string)
+			if !ok {
+				return InstanceCNS{}, nil, fmt.Errorf("invalid %s tag value of type %T", k, raw)
+			}
+			nativeCNS.ReversePTR = v
+		case CNSTagServices:
+			v, ok := raw.(string)
+			if !ok {
+				return InstanceCNS{}, nil, fmt.Errorf("invalid %s tag value of type %T", k, raw)
+			}
+			for _, service := range strings.Split(v, ",") {
+				if service = strings.TrimSpace(service); service != "" {
+					nativeCNS.Services = append(nativeCNS.Services, service)
+				}
 			}
-		} else {
-			nativeTags[k] = raw
 		}
 	}
 
-	return nativeCNS, nativeTags
+	return nativeCNS, nativeTags, nil
 }
 
 // toNative() exports a given _Instance (API representation) to its native object
-// format.
+// format. If the CNS tags of the instance are malformed, the instance is still
+// returned along with the error, with an empty CNS and its tags as they are.
 func (api *_Instance) toNative() (*Instance, error) {
 	m := Instance(api.Instance)
-	m.CNS, m.Tags = tagsExtractMeta(api.Tags)
+	cns, tags, err := tagsExtractMeta(api.Tags)
+	if err != nil {
+		m.Tags = api.Tags
+		return &m, err
+	}
+	m.CNS, m.Tags = cns, tags
 	return &m, nil
 }
 
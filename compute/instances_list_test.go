@@ -0,0 +1,47 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestListAll(t *testing.T) {
+	const total = 2500
+	var queries []string
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		page := []map[string]interface{}{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			page = append(page, map[string]interface{}{"id": fmt.Sprintf("i%d", i)})
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	instances, err := c.Instances().ListAll(context.Background(), &ListInstancesInput{
+		State:  "running",
+		Limit:  10,
+		Offset: 20,
+	})
+	if err != nil {
+		t.Fatalf("error listing instances: %s", err)
+	}
+	if len(instances) != total || instances[total-1].ID != fmt.Sprintf("i%d", total-1) {
+		t.Fatalf("expected %d instances, got %d", total, len(instances))
+	}
+
+	expected := []string{
+		"limit=1000&offset=0&state=running",
+		"limit=1000&offset=1000&state=running",
+		"limit=1000&offset=2000&state=running",
+	}
+	if fmt.Sprint(queries) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected queries %v", queries)
+	}
+}
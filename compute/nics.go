@@ -0,0 +1,197 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+const (
+	NICStateProvisioning = "provisioning"
+	NICStateRunning      = "running"
+	NICStateStopped      = "stopped"
+
+	defaultNICPollInterval = 5 * time.Second
+)
+
+type WaitForNICInput struct {
+	InstanceID string
+	MAC        string
+
+	// PollInterval is the delay between checks of the NIC. Defaults to 5
+	// seconds.
+	PollInterval time.Duration
+}
+
+func (input *WaitForNICInput) pollInterval() time.Duration {
+	if input.PollInterval <= 0 {
+		return defaultNICPollInterval
+	}
+	return input.PollInterval
+}
+
+// WaitForNIC polls a NIC added with AddNIC until its state is "running", and
+// returns it. A NIC which disappears after it was seen failed to be added, and
+// WaitForNIC returns an error for it. Use a context with a deadline to bound
+// the wait.
+func (c *InstancesClient) WaitForNIC(ctx context.Context, input *WaitForNICInput) (*NIC, error) {
+	nic, err := c.waitForNIC(ctx, input, func(nic *NIC) bool {
+		return nic.State == NICStateRunning
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing WaitForNIC request: {{err}}", err)
+	}
+	return nic, nil
+}
+
+// waitForNIC polls a NIC until done returns true for it. The NIC may not be
+// visible immediately after it was requested, but once seen it must not
+// disappear: CloudAPI removes NICs which fail to be added.
+func (c *InstancesClient) waitForNIC(ctx context.Context, input *WaitForNICInput, done func(*NIC) bool) (*NIC, error) {
+	ticker := time.NewTicker(input.pollInterval())
+	defer ticker.Stop()
+
+	seen := false
+	for {
+		nic, err := c.GetNIC(ctx, &GetNICInput{
+			InstanceID: input.InstanceID,
+			MAC:        input.MAC,
+		})
+		switch {
+		case isNotFound(err) && seen:
+			return nil, fmt.Errorf("NIC %s of instance %s no longer exists", input.MAC, input.InstanceID)
+		case isNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			seen = true
+			if done(nic) {
+				return nic, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForNICRemoval polls a NIC removed with RemoveNIC until it no longer
+// exists. Use a context with a deadline to bound the wait.
+func (c *InstancesClient) WaitForNICRemoval(ctx context.Context, input *WaitForNICInput) error {
+	ticker := time.NewTicker(input.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		_, err := c.GetNIC(ctx, &GetNICInput{
+			InstanceID: input.InstanceID,
+			MAC:        input.MAC,
+		})
+		if isNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return errwrap.Wrapf("Error executing WaitForNICRemoval request: {{err}}", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errwrap.Wrapf("Error executing WaitForNICRemoval request: {{err}}", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+type UpdateNICInput struct {
+	InstanceID string `json:"-"`
+	MAC        string `json:"-"`
+
+	// Primary makes the NIC the primary NIC of the instance. Setting it to
+	// false has no effect; make another NIC primary instead.
+	Primary bool `json:"primary,omitempty"`
+}
+
+// UpdateNIC updates the properties of a NIC which CloudAPI allows to be
+// changed, currently only whether it is the primary NIC. Like AddNIC, this
+// causes the instance to restart; use WaitForNIC to wait for it to come back.
+func (c *InstancesClient) UpdateNIC(ctx context.Context, input *UpdateNICInput) (*NIC, error) {
+	mac := strings.Replace(input.MAC, ":", "", -1)
+	path := fmt.Sprintf("/%s/machines/%s/nics/%s", c.client.AccountName, input.InstanceID, mac)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing UpdateNIC request: {{err}}", err)
+	}
+
+	var result *NIC
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding UpdateNIC response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type SetPrimaryNICInput struct {
+	InstanceID string
+	MAC        string
+
+	// PollInterval is the delay between checks while waiting for the NIC.
+	// Defaults to 5 seconds.
+	PollInterval time.Duration
+}
+
+// SetPrimaryNIC makes a NIC the primary NIC of an instance and waits until the
+// instance has restarted with it as primary NIC. It does nothing if the NIC
+// is already primary.
+func (c *InstancesClient) SetPrimaryNIC(ctx context.Context, input *SetPrimaryNICInput) (*NIC, error) {
+	nic, err := c.GetNIC(ctx, &GetNICInput{
+		InstanceID: input.InstanceID,
+		MAC:        input.MAC,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing SetPrimaryNIC request: {{err}}", err)
+	}
+	if nic.Primary {
+		return nic, nil
+	}
+
+	_, err = c.UpdateNIC(ctx, &UpdateNICInput{
+		InstanceID: input.InstanceID,
+		MAC:        input.MAC,
+		Primary:    true,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing SetPrimaryNIC request: {{err}}", err)
+	}
+
+	// The NIC only becomes primary once the instance has restarted. The
+	// restart may finish between two polls, so a running primary NIC is
+	// enough, whether or not it was seen restarting.
+	nic, err = c.waitForNIC(ctx, &WaitForNICInput{
+		InstanceID:   input.InstanceID,
+		MAC:          input.MAC,
+		PollInterval: input.PollInterval,
+	}, func(nic *NIC) bool {
+		return nic.Primary && nic.State == NICStateRunning
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing SetPrimaryNIC request: {{err}}", err)
+	}
+	return nic, nil
+}
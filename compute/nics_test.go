@@ -0,0 +1,171 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testNotFound = `{"code": "ResourceNotFound", "message": "nic not found"}`
+
+func TestWaitForNIC(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusNotFound, testNotFound},
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "state": "provisioning"}`},
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "state": "running"}`},
+	))
+
+	nic, err := c.Instances().WaitForNIC(context.Background(), &WaitForNICInput{
+		InstanceID:   "i1",
+		MAC:          "90:b8:d0:57:53:70",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("error waiting for NIC: %s", err)
+	}
+	if nic.State != NICStateRunning {
+		t.Fatalf("unexpected NIC state %q", nic.State)
+	}
+}
+
+func TestWaitForNIC_Failed(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "state": "provisioning"}`},
+		testResponse{http.StatusNotFound, testNotFound},
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := c.Instances().WaitForNIC(ctx, &WaitForNICInput{
+		InstanceID:   "i1",
+		MAC:          "90:b8:d0:57:53:70",
+		PollInterval: time.Millisecond,
+	})
+	if err == nil || ctx.Err() != nil {
+		t.Fatalf("expected WaitForNIC to fail before the deadline, got %v", err)
+	}
+}
+
+func TestWaitForNICRemoval(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "state": "running"}`},
+		testResponse{http.StatusNotFound, testNotFound},
+	))
+
+	err := c.Instances().WaitForNICRemoval(context.Background(), &WaitForNICInput{
+		InstanceID:   "i1",
+		MAC:          "90:b8:d0:57:53:70",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("error waiting for NIC removal: %s", err)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	c := testComputeClient(t, testResponses(
+		testResponse{http.StatusNotFound, testNotFound},
+	))
+
+	_, err := c.Images().Get(context.Background(), &GetImageInput{ImageID: "img"})
+	if !isNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if isNotFound(nil) {
+		t.Fatal("expected nil not to be a not found error")
+	}
+}
+
+func TestUpdateNIC(t *testing.T) {
+	var method, path string
+	var body map[string]interface{}
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"mac": "90b8d0575370", "primary": true, "state": "running"}`))
+	})
+
+	nic, err := c.Instances().UpdateNIC(context.Background(), &UpdateNICInput{
+		InstanceID: "i1",
+		MAC:        "90:b8:d0:57:53:70",
+		Primary:    true,
+	})
+	if err != nil {
+		t.Fatalf("error updating NIC: %s", err)
+	}
+	if !nic.Primary {
+		t.Fatal("expected NIC to be primary")
+	}
+	if method != http.MethodPost || path != "/acct/machines/i1/nics/90b8d0575370" {
+		t.Fatalf("unexpected request %s %s", method, path)
+	}
+	if len(body) != 1 || body["primary"] != true {
+		t.Fatalf("unexpected request body %v", body)
+	}
+}
+
+func TestSetPrimaryNIC(t *testing.T) {
+	var updated bool
+	gets := testResponses(
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "primary": false, "state": "running"}`},
+		// Still running right after the update, before the restart.
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "primary": false, "state": "running"}`},
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "primary": true, "state": "stopped"}`},
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "primary": true, "state": "running"}`},
+	)
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			updated = true
+			w.Write([]byte(`{"mac": "90b8d0575370", "primary": false, "state": "running"}`))
+			return
+		}
+		gets(w, r)
+	})
+
+	nic, err := c.Instances().SetPrimaryNIC(context.Background(), &SetPrimaryNICInput{
+		InstanceID:   "i1",
+		MAC:          "90:b8:d0:57:53:70",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("error setting primary NIC: %s", err)
+	}
+	if !updated {
+		t.Fatal("expected NIC to be updated")
+	}
+	if !nic.Primary || nic.State != NICStateRunning {
+		t.Fatalf("unexpected NIC %+v", nic)
+	}
+}
+
+func TestSetPrimaryNIC_RestartBetweenPolls(t *testing.T) {
+	// The instance restarts between the update and the first poll, so the
+	// NIC is never seen outside the running state.
+	gets := testResponses(
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "primary": false, "state": "running"}`},
+		testResponse{http.StatusOK, `{"mac": "90b8d0575370", "primary": true, "state": "running"}`},
+	)
+	c := testComputeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"mac": "90b8d0575370", "primary": false, "state": "running"}`))
+			return
+		}
+		gets(w, r)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	nic, err := c.Instances().SetPrimaryNIC(ctx, &SetPrimaryNICInput{
+		InstanceID:   "i1",
+		MAC:          "90:b8:d0:57:53:70",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("error setting primary NIC: %s", err)
+	}
+	if !nic.Primary || nic.State != NICStateRunning {
+		t.Fatalf("unexpected NIC %+v", nic)
+	}
+}
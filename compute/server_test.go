@@ -0,0 +1,35 @@
+package compute
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joyent/triton-go/testutils/testclient"
+)
+
+// testComputeClient returns a ComputeClient for the account "acct" whose
+// requests are served by handler.
+func testComputeClient(t *testing.T, handler http.HandlerFunc) *ComputeClient {
+	return newComputeClient(testclient.New(t, handler))
+}
+
+// testResponse is a canned response of a fake CloudAPI endpoint.
+type testResponse struct {
+	status int
+	body   string
+}
+
+// testResponses returns a handler serving responses in order, repeating the
+// last one once all have been served.
+func testResponses(responses ...testResponse) http.HandlerFunc {
+	i := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := responses[i]
+		if i < len(responses)-1 {
+			i++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.status)
+		w.Write([]byte(response.body))
+	}
+}
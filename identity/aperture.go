@@ -0,0 +1,626 @@
+package identity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file models the Aperture policy language used in the rules of a
+// Policy, e.g.
+//
+//	CAN listmachines AND getmachine
+//	CAN createmachine WHEN sourceip = 10.0.0.0/8 AND NOT day = Sunday
+//
+// ParsePolicyRule turns rule text into a PolicyRule, which can be validated and
+// formatted back into canonical text without a round trip to CloudAPI.
+
+const (
+	PolicyOperatorAnd = "and"
+	PolicyOperatorOr  = "or"
+	PolicyOperatorNot = "not"
+
+	PolicyOperatorEquals         = "="
+	PolicyOperatorNotEquals      = "!="
+	PolicyOperatorLessThan       = "<"
+	PolicyOperatorLessOrEqual    = "<="
+	PolicyOperatorGreaterThan    = ">"
+	PolicyOperatorGreaterOrEqual = ">="
+	PolicyOperatorIn             = "in"
+)
+
+// PolicyCondition is a node of the condition following WHEN in a rule. It is
+// either a logical operator over Operands, or a comparison of an Attribute
+// with Values.
+type PolicyCondition struct {
+	// Operator is one of the PolicyOperator constants.
+	Operator string
+
+	// Operands are the conditions combined by "and", "or" and "not".
+	Operands []*PolicyCondition
+
+	// Attribute is the request attribute compared, e.g. "sourceip".
+	Attribute string
+
+	// Values holds the value compared against, or every value of an "in"
+	// list.
+	Values []string
+}
+
+// PolicyAnd returns a condition which holds when every operand holds.
+func PolicyAnd(operands ...*PolicyCondition) *PolicyCondition {
+	return &PolicyCondition{Operator: PolicyOperatorAnd, Operands: operands}
+}
+
+// PolicyOr returns a condition which holds when any operand holds.
+func PolicyOr(operands ...*PolicyCondition) *PolicyCondition {
+	return &PolicyCondition{Operator: PolicyOperatorOr, Operands: operands}
+}
+
+// PolicyNot returns a condition which holds when operand does not.
+func PolicyNot(operand *PolicyCondition) *PolicyCondition {
+	return &PolicyCondition{Operator: PolicyOperatorNot, Operands: []*PolicyCondition{operand}}
+}
+
+// PolicyCompare returns a condition comparing an attribute with one or more
+// values.
+func PolicyCompare(attribute, operator string, values ...string) *PolicyCondition {
+	return &PolicyCondition{Operator: operator, Attribute: attribute, Values: values}
+}
+
+func (c *PolicyCondition) precedence() int {
+	switch c.Operator {
+	case PolicyOperatorOr:
+		return 1
+	case PolicyOperatorAnd:
+		return 2
+	case PolicyOperatorNot:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// String formats the condition in canonical form.
+func (c *PolicyCondition) String() string {
+	buf := &bytes.Buffer{}
+	c.format(buf, 0)
+	return buf.String()
+}
+
+func (c *PolicyCondition) format(buf *bytes.Buffer, minPrecedence int) {
+	precedence := c.precedence()
+	if precedence < minPrecedence {
+		buf.WriteString("(")
+		defer buf.WriteString(")")
+	}
+
+	switch c.Operator {
+	case PolicyOperatorAnd, PolicyOperatorOr:
+		for i, operand := range c.Operands {
+			if i > 0 {
+				fmt.Fprintf(buf, " %s ", strings.ToUpper(c.Operator))
+			}
+			operand.format(buf, precedence)
+		}
+	case PolicyOperatorNot:
+		buf.WriteString("NOT ")
+		if len(c.Operands) > 0 {
+			c.Operands[0].format(buf, precedence)
+		}
+	case PolicyOperatorIn:
+		values := make([]string, 0, len(c.Values))
+		for _, value := range c.Values {
+			values = append(values, quotePolicyWord(value))
+		}
+		fmt.Fprintf(buf, "%s IN (%s)", c.Attribute, strings.Join(values, ", "))
+	default:
+		value := ""
+		if len(c.Values) > 0 {
+			value = c.Values[0]
+		}
+		fmt.Fprintf(buf, "%s %s %s", c.Attribute, c.Operator, quotePolicyWord(value))
+	}
+}
+
+// Validate checks the condition is well formed.
+func (c *PolicyCondition) Validate() error {
+	switch c.Operator {
+	case PolicyOperatorAnd, PolicyOperatorOr:
+		if len(c.Operands) < 2 {
+			return fmt.Errorf("%s requires at least two operands", strings.ToUpper(c.Operator))
+		}
+	case PolicyOperatorNot:
+		if len(c.Operands) != 1 {
+			return errors.New("NOT requires exactly one operand")
+		}
+	case PolicyOperatorEquals, PolicyOperatorNotEquals, PolicyOperatorLessThan,
+		PolicyOperatorLessOrEqual, PolicyOperatorGreaterThan, PolicyOperatorGreaterOrEqual:
+		if c.Attribute == "" {
+			return fmt.Errorf("comparison %s has no attribute", c.Operator)
+		}
+		if len(c.Values) != 1 {
+			return fmt.Errorf("comparison of %s requires exactly one value", c.Attribute)
+		}
+		return nil
+	case PolicyOperatorIn:
+		if c.Attribute == "" {
+			return errors.New("IN has no attribute")
+		}
+		if len(c.Values) == 0 {
+			return fmt.Errorf("IN list of %s is empty", c.Attribute)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+
+	for _, operand := range c.Operands {
+		if operand == nil {
+			return fmt.Errorf("%s has an empty operand", strings.ToUpper(c.Operator))
+		}
+		if err := operand.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PolicyRule is a parsed Aperture rule.
+type PolicyRule struct {
+	// Principals the rule applies to. Usually empty in Triton policies,
+	// where the rule applies to the members of the roles given the policy.
+	Principals []string
+
+	// Actions allowed by the rule, e.g. "listmachines".
+	Actions []string
+
+	// Resources the actions are allowed on. Optional.
+	Resources []string
+
+	// Condition under which the rule applies. Optional.
+	Condition *PolicyCondition
+}
+
+// String formats the rule in canonical form. The result of formatting a valid
+// rule can always be parsed back into an equivalent PolicyRule.
+func (r *PolicyRule) String() string {
+	buf := &bytes.Buffer{}
+	if len(r.Principals) > 0 {
+		writePolicyList(buf, r.Principals)
+		buf.WriteString(" ")
+	}
+	buf.WriteString("CAN ")
+	writePolicyList(buf, r.Actions)
+	if len(r.Resources) > 0 {
+		buf.WriteString(" ")
+		writePolicyList(buf, r.Resources)
+	}
+	if r.Condition != nil {
+		buf.WriteString(" WHEN ")
+		r.Condition.format(buf, 0)
+	}
+	return buf.String()
+}
+
+func writePolicyList(buf *bytes.Buffer, items []string) {
+	for i, item := range items {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		buf.WriteString(quotePolicyWord(item))
+	}
+}
+
+var policyActionRegexp = regexp.MustCompile(`^[A-Za-z0-9_:*]+$`)
+
+// Validate checks the rule is well formed.
+func (r *PolicyRule) Validate() error {
+	if len(r.Actions) == 0 {
+		return errors.New("rule has no actions")
+	}
+	for _, action := range r.Actions {
+		if !policyActionRegexp.MatchString(action) {
+			return fmt.Errorf("invalid action %q", action)
+		}
+	}
+	for _, principal := range r.Principals {
+		if principal == "" {
+			return errors.New("principal can not be empty")
+		}
+	}
+	for _, resource := range r.Resources {
+		if resource == "" {
+			return errors.New("resource can not be empty")
+		}
+	}
+	if r.Condition != nil {
+		return r.Condition.Validate()
+	}
+	return nil
+}
+
+// ParsePolicyRules parses and validates every rule, returning the first error
+// found along with the index of the offending rule.
+func ParsePolicyRules(rules []string) ([]*PolicyRule, error) {
+	result := make([]*PolicyRule, 0, len(rules))
+	for i, text := range rules {
+		rule, err := ParsePolicyRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%q): %s", i, text, err)
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// PolicySyntaxError is returned by ParsePolicyRule when rule text can not be
+// parsed.
+type PolicySyntaxError struct {
+	// Offset is the byte offset into the rule text at which the error was
+	// detected.
+	Offset  int
+	Message string
+}
+
+func (e *PolicySyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Message)
+}
+
+var policyKeywords = map[string]bool{
+	"can":  true,
+	"when": true,
+	"if":   true,
+	"and":  true,
+	"or":   true,
+	"not":  true,
+	"in":   true,
+}
+
+const policySpecialChars = `(),=!<>"'`
+
+func isPolicyWordChar(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(policySpecialChars, r)
+}
+
+// quotePolicyWord quotes a word if it would not otherwise be read back as a
+// single word.
+func quotePolicyWord(word string) string {
+	if word == "" || policyKeywords[strings.ToLower(word)] || strings.IndexFunc(word, func(r rune) bool {
+		return !isPolicyWordChar(r)
+	}) >= 0 {
+		return quotePolicyString(word)
+	}
+	return word
+}
+
+// quotePolicyString double-quotes a string the way tokenizePolicyRule reads it
+// back: only quotes and backslashes are escaped, with a backslash, and every
+// other byte is written as is.
+func quotePolicyString(s string) string {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(s[i])
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+type policyTokenKind int
+
+const (
+	policyTokenEOF policyTokenKind = iota
+	policyTokenWord
+	policyTokenString
+	policyTokenLParen
+	policyTokenRParen
+	policyTokenComma
+	policyTokenOperator
+)
+
+type policyToken struct {
+	kind   policyTokenKind
+	text   string
+	offset int
+}
+
+func (t policyToken) describe() string {
+	if t.kind == policyTokenEOF {
+		return "end of rule"
+	}
+	return strconv.Quote(t.text)
+}
+
+func tokenizePolicyRule(text string) ([]policyToken, error) {
+	var tokens []policyToken
+	for i := 0; i < len(text); {
+		r := rune(text[i])
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == '(':
+			tokens = append(tokens, policyToken{policyTokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, policyToken{policyTokenRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, policyToken{policyTokenComma, ",", i})
+			i++
+		case r == '=':
+			tokens = append(tokens, policyToken{policyTokenOperator, "=", i})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			if i+1 < len(text) && text[i+1] == '=' {
+				tokens = append(tokens, policyToken{policyTokenOperator, text[i : i+2], i})
+				i += 2
+				continue
+			}
+			if r == '!' {
+				return nil, &PolicySyntaxError{i, `expected "!="`}
+			}
+			tokens = append(tokens, policyToken{policyTokenOperator, string(r), i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			value := &bytes.Buffer{}
+			for {
+				if i >= len(text) {
+					return nil, &PolicySyntaxError{start, "unterminated string"}
+				}
+				if text[i] == '\\' && i+1 < len(text) {
+					value.WriteByte(text[i+1])
+					i += 2
+					continue
+				}
+				if rune(text[i]) == r {
+					i++
+					break
+				}
+				value.WriteByte(text[i])
+				i++
+			}
+			tokens = append(tokens, policyToken{policyTokenString, value.String(), start})
+		default:
+			start := i
+			for i < len(text) {
+				r, size := utf8.DecodeRuneInString(text[i:])
+				if !isPolicyWordChar(r) {
+					break
+				}
+				i += size
+			}
+			if i == start {
+				return nil, &PolicySyntaxError{i, fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, policyToken{policyTokenWord, text[start:i], start})
+		}
+	}
+
+	tokens = append(tokens, policyToken{policyTokenEOF, "", len(text)})
+	return tokens, nil
+}
+
+type policyParser struct {
+	tokens []policyToken
+	pos    int
+}
+
+func (p *policyParser) peek() policyToken {
+	return p.tokens[p.pos]
+}
+
+func (p *policyParser) next() policyToken {
+	token := p.tokens[p.pos]
+	if token.kind != policyTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *policyParser) errorf(token policyToken, format string, args ...interface{}) error {
+	return &PolicySyntaxError{token.offset, fmt.Sprintf(format, args...)}
+}
+
+func (p *policyParser) isKeyword(token policyToken, keyword string) bool {
+	return token.kind == policyTokenWord && strings.EqualFold(token.text, keyword)
+}
+
+func (p *policyParser) isAnyKeyword(token policyToken) bool {
+	return token.kind == policyTokenWord && policyKeywords[strings.ToLower(token.text)]
+}
+
+// ParsePolicyRule parses an Aperture rule and validates the result. Keywords
+// are matched case-insensitively, and IF may be used in place of WHEN.
+func ParsePolicyRule(text string) (*PolicyRule, error) {
+	tokens, err := tokenizePolicyRule(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &policyParser{tokens: tokens}
+
+	rule := &PolicyRule{}
+	if !p.isKeyword(p.peek(), "CAN") {
+		if rule.Principals, err = p.parseList("principal"); err != nil {
+			return nil, err
+		}
+	}
+	if token := p.next(); !p.isKeyword(token, "CAN") {
+		return nil, p.errorf(token, "expected CAN, found %s", token.describe())
+	}
+	if rule.Actions, err = p.parseList("action"); err != nil {
+		return nil, err
+	}
+
+	token := p.peek()
+	if (token.kind == policyTokenWord && !p.isAnyKeyword(token)) || token.kind == policyTokenString {
+		if rule.Resources, err = p.parseList("resource"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isKeyword(p.peek(), "WHEN") || p.isKeyword(p.peek(), "IF") {
+		p.next()
+		if rule.Condition, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if token := p.next(); token.kind != policyTokenEOF {
+		return nil, p.errorf(token, "unexpected %s", token.describe())
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// parseList parses words separated by commas or AND.
+func (p *policyParser) parseList(what string) ([]string, error) {
+	var items []string
+	for {
+		token := p.next()
+		if !(token.kind == policyTokenWord && !p.isAnyKeyword(token)) && token.kind != policyTokenString {
+			return nil, p.errorf(token, "expected %s, found %s", what, token.describe())
+		}
+		items = append(items, token.text)
+
+		if p.peek().kind != policyTokenComma && !p.isKeyword(p.peek(), "AND") {
+			return items, nil
+		}
+		p.next()
+	}
+}
+
+func (p *policyParser) parseOr() (*PolicyCondition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isKeyword(p.peek(), "OR") {
+		return left, nil
+	}
+
+	condition := PolicyOr(left)
+	for p.isKeyword(p.peek(), "OR") {
+		p.next()
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		condition.Operands = append(condition.Operands, operand)
+	}
+	return condition, nil
+}
+
+func (p *policyParser) parseAnd() (*PolicyCondition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isKeyword(p.peek(), "AND") {
+		return left, nil
+	}
+
+	condition := PolicyAnd(left)
+	for p.isKeyword(p.peek(), "AND") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		condition.Operands = append(condition.Operands, operand)
+	}
+	return condition, nil
+}
+
+func (p *policyParser) parseNot() (*PolicyCondition, error) {
+	if p.isKeyword(p.peek(), "NOT") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return PolicyNot(operand), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *policyParser) parsePrimary() (*PolicyCondition, error) {
+	token := p.next()
+	if token.kind == policyTokenLParen {
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != policyTokenRParen {
+			return nil, p.errorf(closing, "expected ), found %s", closing.describe())
+		}
+		return condition, nil
+	}
+
+	if token.kind != policyTokenWord || p.isAnyKeyword(token) {
+		return nil, p.errorf(token, "expected attribute, found %s", token.describe())
+	}
+	attribute := token.text
+
+	operator := p.next()
+	if p.isKeyword(operator, "IN") {
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return PolicyCompare(attribute, PolicyOperatorIn, values...), nil
+	}
+	if operator.kind != policyTokenOperator {
+		return nil, p.errorf(operator, "expected operator, found %s", operator.describe())
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return PolicyCompare(attribute, operator.text, value), nil
+}
+
+func (p *policyParser) parseValue() (string, error) {
+	token := p.next()
+	if token.kind != policyTokenWord && token.kind != policyTokenString {
+		return "", p.errorf(token, "expected value, found %s", token.describe())
+	}
+	return token.text, nil
+}
+
+func (p *policyParser) parseValueList() ([]string, error) {
+	if token := p.next(); token.kind != policyTokenLParen {
+		return nil, p.errorf(token, "expected (, found %s", token.describe())
+	}
+
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		token := p.next()
+		if token.kind == policyTokenRParen {
+			return values, nil
+		}
+		if token.kind != policyTokenComma {
+			return nil, p.errorf(token, "expected , or ), found %s", token.describe())
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package identity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePolicyRuleCanonical(t *testing.T) {
+	cases := []struct {
+		text      string
+		canonical string
+	}{
+		{
+			`CAN listmachines AND getmachine`,
+			`CAN listmachines AND getmachine`,
+		},
+		{
+			`can listmachines, getmachine`,
+			`CAN listmachines AND getmachine`,
+		},
+		{
+			`Fred CAN getobject /fred/stor/* IF sourceip = 10.0.0.0/8`,
+			`Fred CAN getobject /fred/stor/* WHEN sourceip = 10.0.0.0/8`,
+		},
+		{
+			`CAN createmachine WHEN (day = Monday OR day = Tuesday) AND NOT sourceip = 10.1.0.0/16`,
+			`CAN createmachine WHEN (day = Monday OR day = Tuesday) AND NOT sourceip = 10.1.0.0/16`,
+		},
+		{
+			`CAN deletemachine WHEN a = 1 OR b = 2 AND c != 3`,
+			`CAN deletemachine WHEN a = 1 OR b = 2 AND c != 3`,
+		},
+		{
+			`CAN getmachine WHEN day in (Saturday, 'Sunday') AND time >= 09:00:00`,
+			`CAN getmachine WHEN day IN (Saturday, Sunday) AND time >= 09:00:00`,
+		},
+		{
+			`CAN putobject WHEN useragent = "curl and more"`,
+			`CAN putobject WHEN useragent = "curl and more"`,
+		},
+		{
+			`CAN listmachines WHEN NOT (a = 1 AND b = 2)`,
+			`CAN listmachines WHEN NOT (a = 1 AND b = 2)`,
+		},
+	}
+
+	for _, c := range cases {
+		rule, err := ParsePolicyRule(c.text)
+		if err != nil {
+			t.Errorf("ParsePolicyRule(%q): %s", c.text, err)
+			continue
+		}
+		if got := rule.String(); got != c.canonical {
+			t.Errorf("ParsePolicyRule(%q).String() = %q, want %q", c.text, got, c.canonical)
+		}
+
+		reparsed, err := ParsePolicyRule(rule.String())
+		if err != nil {
+			t.Errorf("ParsePolicyRule(%q): %s", rule.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(rule, reparsed) {
+			t.Errorf("round trip of %q changed the rule", c.text)
+		}
+	}
+}
+
+func TestParsePolicyRuleStructure(t *testing.T) {
+	rule, err := ParsePolicyRule(`CAN getobject /a/stor/x, /a/stor/y WHEN a = 1 OR b = 2 AND c = 3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &PolicyRule{
+		Actions:   []string{"getobject"},
+		Resources: []string{"/a/stor/x", "/a/stor/y"},
+		Condition: PolicyOr(
+			PolicyCompare("a", PolicyOperatorEquals, "1"),
+			PolicyAnd(
+				PolicyCompare("b", PolicyOperatorEquals, "2"),
+				PolicyCompare("c", PolicyOperatorEquals, "3"),
+			),
+		),
+	}
+	if !reflect.DeepEqual(rule, expected) {
+		t.Errorf("expected %s, got %s", expected, rule)
+	}
+}
+
+func TestParsePolicyRuleErrors(t *testing.T) {
+	cases := []string{
+		``,
+		`listmachines`,
+		`CAN`,
+		`CAN listmachines WHEN`,
+		`CAN listmachines WHEN a =`,
+		`CAN listmachines WHEN a ! b`,
+		`CAN listmachines WHEN (a = 1`,
+		`CAN listmachines WHEN a IN ()`,
+		`CAN listmachines WHEN a = 1 b`,
+		`CAN list-machines`,
+		`CAN listmachines WHEN a = "open`,
+	}
+
+	for _, text := range cases {
+		if _, err := ParsePolicyRule(text); err == nil {
+			t.Errorf("ParsePolicyRule(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestParsePolicyRules(t *testing.T) {
+	if _, err := ParsePolicyRules([]string{"CAN listmachines", "CAN"}); err == nil {
+		t.Error("expected error for invalid second rule")
+	}
+}
+
+func TestPolicyRuleQuotedValuesRoundTrip(t *testing.T) {
+	values := []string{
+		"tab\there",
+		"line\nbreak\r",
+		"bell\x07 and nul\x00",
+		`quote " and backslash \ end\`,
+		"single ' quote",
+		"café",
+		"日本語 text",
+		" non-breaking",
+		"and",
+	}
+
+	for _, value := range values {
+		rule := &PolicyRule{
+			Actions:   []string{"getobject"},
+			Resources: []string{value},
+			Condition: PolicyOr(
+				PolicyCompare("useragent", PolicyOperatorEquals, value),
+				PolicyCompare("day", PolicyOperatorIn, value, "Monday"),
+			),
+		}
+
+		reparsed, err := ParsePolicyRule(rule.String())
+		if err != nil {
+			t.Errorf("ParsePolicyRule(%q): %s", rule.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(rule, reparsed) {
+			t.Errorf("round trip of %q changed the rule to %q", value, reparsed.String())
+		}
+	}
+}
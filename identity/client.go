@@ -19,10 +19,11 @@ func newIdentityClient(client *client.Client) *IdentityClient {
 // resources within CloudAPI
 func NewClient(config *triton.ClientConfig) (*IdentityClient, error) {
 	// TODO: Utilize config interface within the function itself
-	client, err := client.New(config.TritonURL, config.MantaURL, config.AccountName, config.Signers...)
+	client, err := client.NewForUser(config.TritonURL, config.MantaURL, config.AccountName, config.Username, config.Signers...)
 	if err != nil {
 		return nil, err
 	}
+	client.AsRole = config.AsRole
 	return newIdentityClient(client), nil
 }
 
@@ -31,3 +32,21 @@ func NewClient(config *triton.ClientConfig) (*IdentityClient, error) {
 func (c *IdentityClient) Roles() *RolesClient {
 	return &RolesClient{c.Client}
 }
+
+// Users returns a UsersClient used for accessing functions pertaining to
+// User functionality in the Triton API.
+func (c *IdentityClient) Users() *UsersClient {
+	return &UsersClient{c.Client}
+}
+
+// Policies returns a PoliciesClient used for accessing functions pertaining
+// to Policy functionality in the Triton API.
+func (c *IdentityClient) Policies() *PoliciesClient {
+	return &PoliciesClient{c.Client}
+}
+
+// RoleTags returns a RoleTagsClient used for accessing functions pertaining
+// to role tags on resources in the Triton API.
+func (c *IdentityClient) RoleTags() *RoleTagsClient {
+	return &RoleTagsClient{c.Client}
+}
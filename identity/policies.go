@@ -0,0 +1,185 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+type PoliciesClient struct {
+	client *client.Client
+}
+
+// Policy is a named set of Aperture rules which can be given to roles.
+type Policy struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Rules       []string `json:"rules"`
+	Description string   `json:"description"`
+}
+
+// ParseRules parses every rule of the policy.
+func (p *Policy) ParseRules() ([]*PolicyRule, error) {
+	return ParsePolicyRules(p.Rules)
+}
+
+type ListPoliciesInput struct{}
+
+func (c *PoliciesClient) List(ctx context.Context, _ *ListPoliciesInput) ([]*Policy, error) {
+	path := fmt.Sprintf("/%s/policies", c.client.AccountName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListPolicies request: {{err}}", err)
+	}
+
+	var result []*Policy
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ListPolicies response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type GetPolicyInput struct {
+	PolicyID string
+}
+
+func (c *PoliciesClient) Get(ctx context.Context, input *GetPolicyInput) (*Policy, error) {
+	path := fmt.Sprintf("/%s/policies/%s", c.client.AccountName, input.PolicyID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetPolicy request: {{err}}", err)
+	}
+
+	var result *Policy
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding GetPolicy response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// CreatePolicyInput represents the options that can be specified
+// when creating a new policy.
+type CreatePolicyInput struct {
+	// Name of the policy. Required.
+	Name string `json:"name"`
+
+	// Aperture rules of the policy, e.g. "CAN listmachines". Required.
+	Rules []string `json:"rules"`
+
+	// Description of the policy. Optional.
+	Description string `json:"description,omitempty"`
+}
+
+// Create creates a policy. Its rules are validated locally before the request
+// is sent.
+func (c *PoliciesClient) Create(ctx context.Context, input *CreatePolicyInput) (*Policy, error) {
+	if _, err := ParsePolicyRules(input.Rules); err != nil {
+		return nil, errwrap.Wrapf("Error validating CreatePolicy rules: {{err}}", err)
+	}
+
+	path := fmt.Sprintf("/%s/policies", c.client.AccountName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing CreatePolicy request: {{err}}", err)
+	}
+
+	var result *Policy
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding CreatePolicy response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// UpdatePolicyInput represents the options that can be specified
+// when updating a policy. Anything but ID can be modified.
+type UpdatePolicyInput struct {
+	// ID of the policy to modify. Required.
+	PolicyID string `json:"-"`
+
+	Name        string   `json:"name,omitempty"`
+	Rules       []string `json:"rules,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Update updates a policy. Its rules, if given, are validated locally before
+// the request is sent.
+func (c *PoliciesClient) Update(ctx context.Context, input *UpdatePolicyInput) (*Policy, error) {
+	if _, err := ParsePolicyRules(input.Rules); err != nil {
+		return nil, errwrap.Wrapf("Error validating UpdatePolicy rules: {{err}}", err)
+	}
+
+	path := fmt.Sprintf("/%s/policies/%s", c.client.AccountName, input.PolicyID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing UpdatePolicy request: {{err}}", err)
+	}
+
+	var result *Policy
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding UpdatePolicy response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type DeletePolicyInput struct {
+	PolicyID string
+}
+
+func (c *PoliciesClient) Delete(ctx context.Context, input *DeletePolicyInput) error {
+	path := fmt.Sprintf("/%s/policies/%s", c.client.AccountName, input.PolicyID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodDelete,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return errwrap.Wrapf("Error executing DeletePolicy request: {{err}}", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// Resource types which role tags can be attached to in CloudAPI.
+const (
+	RoleTagResourceMachines      = "machines"
+	RoleTagResourceImages        = "images"
+	RoleTagResourceNetworks      = "networks"
+	RoleTagResourcePackages      = "packages"
+	RoleTagResourceUsers         = "users"
+	RoleTagResourceRoles         = "roles"
+	RoleTagResourcePolicies      = "policies"
+	RoleTagResourceKeys          = "keys"
+	RoleTagResourceFirewallRules = "fwrules"
+)
+
+var roleTagResourceTypes = map[string]bool{
+	RoleTagResourceMachines:      true,
+	RoleTagResourceImages:        true,
+	RoleTagResourceNetworks:      true,
+	RoleTagResourcePackages:      true,
+	RoleTagResourceUsers:         true,
+	RoleTagResourceRoles:         true,
+	RoleTagResourcePolicies:      true,
+	RoleTagResourceKeys:          true,
+	RoleTagResourceFirewallRules: true,
+}
+
+// RoleTagsClient reads and replaces the role tags of CloudAPI resources, which
+// grant the members of the tagged roles access to them. Role tags on Manta
+// objects and directories are managed through storage.RoleTagsClient.
+type RoleTagsClient struct {
+	client *client.Client
+}
+
+func (c *RoleTagsClient) resourcePath(resourceType, resourceID string) (string, error) {
+	if !roleTagResourceTypes[resourceType] {
+		return "", fmt.Errorf("role tags are not supported on resource type %q", resourceType)
+	}
+	path := fmt.Sprintf("/%s/%s", c.client.AccountName, resourceType)
+	if resourceID != "" {
+		path = fmt.Sprintf("%s/%s", path, resourceID)
+	}
+	return path, nil
+}
+
+type GetRoleTagsInput struct {
+	// ResourceType is one of the RoleTagResource constants.
+	ResourceType string
+
+	// ResourceID is the ID or name of the resource. If empty, the role tags
+	// of the collection itself are returned, e.g. those of /:login/machines.
+	ResourceID string
+}
+
+// Get returns the role tags of a resource, as reported in the role-tag header
+// of CloudAPI responses.
+func (c *RoleTagsClient) Get(ctx context.Context, input *GetRoleTagsInput) ([]string, error) {
+	path, err := c.resourcePath(input.ResourceType, input.ResourceID)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetRoleTags request: {{err}}", err)
+	}
+
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	response, err := c.client.ExecuteRequestRaw(ctx, reqInputs)
+	if response != nil {
+		defer response.Body.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetRoleTags request: {{err}}", err)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, errwrap.Wrapf("Error executing GetRoleTags request: {{err}}",
+			c.client.DecodeError(response.StatusCode, response.Body))
+	}
+
+	return ParseRoleTagHeader(response.Header.Get("role-tag")), nil
+}
+
+// ParseRoleTagHeader splits the comma-separated value of a role-tag header.
+func ParseRoleTagHeader(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+type ReplaceRoleTagsInput struct {
+	ResourceType string `json:"-"`
+	ResourceID   string `json:"-"`
+
+	// RoleTags are the names of the roles given access to the resource.
+	// An empty list removes every role tag.
+	RoleTags []string `json:"role-tag"`
+}
+
+// Replace replaces every role tag of a resource, returning the role tags now
+// set on it.
+func (c *RoleTagsClient) Replace(ctx context.Context, input *ReplaceRoleTagsInput) ([]string, error) {
+	path, err := c.resourcePath(input.ResourceType, input.ResourceID)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ReplaceRoleTags request: {{err}}", err)
+	}
+	body := *input
+	if body.RoleTags == nil {
+		body.RoleTags = []string{}
+	}
+
+	reqInputs := client.RequestInput{
+		Method: http.MethodPut,
+		Path:   path,
+		Body:   &body,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ReplaceRoleTags request: {{err}}", err)
+	}
+
+	var result struct {
+		RoleTags []string `json:"role-tag"`
+	}
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ReplaceRoleTags response: {{err}}", err)
+	}
+
+	return result.RoleTags, nil
+}
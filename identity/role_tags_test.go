@@ -0,0 +1,19 @@
+package identity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRoleTagHeader(t *testing.T) {
+	cases := map[string][]string{
+		"":                 {},
+		"ops":              {"ops"},
+		"ops, dev,,admin ": {"ops", "dev", "admin"},
+	}
+	for header, expected := range cases {
+		if got := ParseRoleTagHeader(header); !reflect.DeepEqual(got, expected) {
+			t.Errorf("ParseRoleTagHeader(%q) = %v, want %v", header, got, expected)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/account"
+	"github.com/joyent/triton-go/client"
+)
+
+type UsersClient struct {
+	client *client.Client
+}
+
+// User represents a sub-user of an account.
+type User struct {
+	ID           string    `json:"id"`
+	Login        string    `json:"login"`
+	EmailAddress string    `json:"email"`
+	CompanyName  string    `json:"companyName"`
+	FirstName    string    `json:"firstName"`
+	LastName     string    `json:"lastName"`
+	Address      string    `json:"address"`
+	PostalCode   string    `json:"postalCode"`
+	City         string    `json:"city"`
+	State        string    `json:"state"`
+	Country      string    `json:"country"`
+	Phone        string    `json:"phone"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+}
+
+type ListUsersInput struct{}
+
+func (c *UsersClient) List(ctx context.Context, _ *ListUsersInput) ([]*User, error) {
+	path := fmt.Sprintf("/%s/users", c.client.AccountName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListUsers request: {{err}}", err)
+	}
+
+	var result []*User
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ListUsers response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type GetUserInput struct {
+	// UserID is the ID or login of the user.
+	UserID string
+}
+
+func (c *UsersClient) Get(ctx context.Context, input *GetUserInput) (*User, error) {
+	path := fmt.Sprintf("/%s/users/%s", c.client.AccountName, input.UserID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetUser request: {{err}}", err)
+	}
+
+	var result *User
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding GetUser response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// CreateUserInput represents the options that can be specified
+// when creating a new user.
+type CreateUserInput struct {
+	// Login, EmailAddress and Password are required.
+	Login        string `json:"login"`
+	EmailAddress string `json:"email"`
+	Password     string `json:"password"`
+
+	CompanyName string `json:"companyName,omitempty"`
+	FirstName   string `json:"firstName,omitempty"`
+	LastName    string `json:"lastName,omitempty"`
+	Address     string `json:"address,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	City        string `json:"city,omitempty"`
+	State       string `json:"state,omitempty"`
+	Country     string `json:"country,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+func (c *UsersClient) Create(ctx context.Context, input *CreateUserInput) (*User, error) {
+	path := fmt.Sprintf("/%s/users", c.client.AccountName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing CreateUser request: {{err}}", err)
+	}
+
+	var result *User
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding CreateUser response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// UpdateUserInput represents the options that can be specified
+// when updating a user. Anything but ID and password can be modified;
+// use ChangePassword to change the password.
+type UpdateUserInput struct {
+	// ID of the user to modify. Required.
+	UserID string `json:"-"`
+
+	Login        string `json:"login,omitempty"`
+	EmailAddress string `json:"email,omitempty"`
+	CompanyName  string `json:"companyName,omitempty"`
+	FirstName    string `json:"firstName,omitempty"`
+	LastName     string `json:"lastName,omitempty"`
+	Address      string `json:"address,omitempty"`
+	PostalCode   string `json:"postalCode,omitempty"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state,omitempty"`
+	Country      string `json:"country,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+func (c *UsersClient) Update(ctx context.Context, input *UpdateUserInput) (*User, error) {
+	path := fmt.Sprintf("/%s/users/%s", c.client.AccountName, input.UserID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing UpdateUser request: {{err}}", err)
+	}
+
+	var result *User
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding UpdateUser response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type DeleteUserInput struct {
+	UserID string
+}
+
+func (c *UsersClient) Delete(ctx context.Context, input *DeleteUserInput) error {
+	path := fmt.Sprintf("/%s/users/%s", c.client.AccountName, input.UserID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodDelete,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return errwrap.Wrapf("Error executing DeleteUser request: {{err}}", err)
+	}
+
+	return nil
+}
+
+type ChangeUserPasswordInput struct {
+	UserID               string `json:"-"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+func (c *UsersClient) ChangePassword(ctx context.Context, input *ChangeUserPasswordInput) (*User, error) {
+	path := fmt.Sprintf("/%s/users/%s/change_password", c.client.AccountName, input.UserID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ChangeUserPassword request: {{err}}", err)
+	}
+
+	var result *User
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ChangeUserPassword response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type ListUserKeysInput struct {
+	UserID string
+}
+
+// ListKeys lists all public keys we have on record for a user.
+func (c *UsersClient) ListKeys(ctx context.Context, input *ListUserKeysInput) ([]*account.Key, error) {
+	path := fmt.Sprintf("/%s/users/%s/keys", c.client.AccountName, input.UserID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListUserKeys request: {{err}}", err)
+	}
+
+	var result []*account.Key
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ListUserKeys response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type GetUserKeyInput struct {
+	UserID string
+
+	// KeyName is the name or fingerprint of the key.
+	KeyName string
+}
+
+func (c *UsersClient) GetKey(ctx context.Context, input *GetUserKeyInput) (*account.Key, error) {
+	path := fmt.Sprintf("/%s/users/%s/keys/%s", c.client.AccountName, input.UserID, input.KeyName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetUserKey request: {{err}}", err)
+	}
+
+	var result *account.Key
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding GetUserKey response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// CreateUserKeyInput represents the option that can be specified
+// when creating a new key for a user.
+type CreateUserKeyInput struct {
+	UserID string `json:"-"`
+
+	// Name of the key. Optional.
+	Name string `json:"name,omitempty"`
+
+	// OpenSSH-formatted public key.
+	Key string `json:"key"`
+}
+
+// CreateKey uploads a new OpenSSH key for a user, for use in HTTP signing and
+// SSH.
+func (c *UsersClient) CreateKey(ctx context.Context, input *CreateUserKeyInput) (*account.Key, error) {
+	path := fmt.Sprintf("/%s/users/%s/keys", c.client.AccountName, input.UserID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing CreateUserKey request: {{err}}", err)
+	}
+
+	var result *account.Key
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding CreateUserKey response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type DeleteUserKeyInput struct {
+	UserID  string
+	KeyName string
+}
+
+func (c *UsersClient) DeleteKey(ctx context.Context, input *DeleteUserKeyInput) error {
+	path := fmt.Sprintf("/%s/users/%s/keys/%s", c.client.AccountName, input.UserID, input.KeyName)
+	reqInputs := client.RequestInput{
+		Method: http.MethodDelete,
+		Path:   path,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return errwrap.Wrapf("Error executing DeleteUserKey request: {{err}}", err)
+	}
+
+	return nil
+}
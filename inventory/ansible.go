@@ -0,0 +1,161 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/joyent/triton-go/compute"
+	"github.com/joyent/triton-go/network"
+)
+
+var ansibleGroupInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// AnsibleGroup is a group of an Ansible inventory.
+type AnsibleGroup struct {
+	Hosts []string               `json:"hosts"`
+	Vars  map[string]interface{} `json:"vars,omitempty"`
+}
+
+// AnsibleMeta holds the variables of every host, so that Ansible does not
+// need to call the inventory script once per host.
+type AnsibleMeta struct {
+	HostVars map[string]map[string]interface{} `json:"hostvars"`
+}
+
+// AnsibleInventory is the output of an Ansible dynamic inventory script, as
+// expected from its --list option once encoded to JSON.
+type AnsibleInventory struct {
+	Groups map[string]*AnsibleGroup
+	Meta   AnsibleMeta
+}
+
+// MarshalJSON implements json.Marshaler, placing groups and _meta at the top
+// level as Ansible expects.
+func (i *AnsibleInventory) MarshalJSON() ([]byte, error) {
+	values := map[string]interface{}{}
+	for name, group := range i.Groups {
+		values[name] = group
+	}
+	values["_meta"] = i.Meta
+	return json.Marshal(values)
+}
+
+// AnsibleInventoryInput represents parameters to AnsibleInventoryFor.
+type AnsibleInventoryInput struct {
+	Instances []*compute.Instance
+
+	// Networks are used to find instances which are only on fabric networks.
+	Networks []*network.Network
+
+	// Images are used to name image groups. Instances whose image is not
+	// listed are grouped by image ID.
+	Images []*compute.Image
+
+	// Bastion is the host instances only on fabric networks are reached
+	// through, e.g. "root@bastion.example.com". If it names one of the
+	// instances, the address of that instance is used. Optional.
+	Bastion string
+
+	// User is set as ansible_user of every host. Optional.
+	User string
+
+	// IncludeStopped includes instances which are not running.
+	IncludeStopped bool
+}
+
+// AnsibleInventoryFor returns an Ansible inventory of instances. Hosts are
+// named after their instance and grouped by tag ("tag_<key>_<value>"), image
+// ("image_<name>") and package ("package_<name>"), with every host in "all".
+func AnsibleInventoryFor(input *AnsibleInventoryInput) *AnsibleInventory {
+	imageNames := map[string]string{}
+	for _, image := range input.Images {
+		imageNames[image.ID] = image.Name
+	}
+
+	inventory := &AnsibleInventory{
+		Groups: map[string]*AnsibleGroup{
+			"all": {Hosts: []string{}},
+		},
+		Meta: AnsibleMeta{
+			HostVars: map[string]map[string]interface{}{},
+		},
+	}
+	addToGroup := func(group, hostName string) {
+		group = ansibleGroupName(group)
+		if _, ok := inventory.Groups[group]; !ok {
+			inventory.Groups[group] = &AnsibleGroup{}
+		}
+		inventory.Groups[group].Hosts = append(inventory.Groups[group].Hosts, hostName)
+	}
+
+	hosts := resolveHosts(&hostsInput{
+		instances:      input.Instances,
+		networks:       input.Networks,
+		bastion:        input.Bastion,
+		includeStopped: input.IncludeStopped,
+	})
+	proxyJump := input.Bastion
+	for _, h := range hosts {
+		if h.name == input.Bastion {
+			proxyJump = h.address
+		}
+	}
+
+	for _, h := range hosts {
+		instance := h.instance
+		inventory.Groups["all"].Hosts = append(inventory.Groups["all"].Hosts, h.name)
+
+		tagKeys := make([]string, 0, len(instance.Tags))
+		for key := range instance.Tags {
+			tagKeys = append(tagKeys, key)
+		}
+		sort.Strings(tagKeys)
+		for _, key := range tagKeys {
+			addToGroup(fmt.Sprintf("tag_%s_%v", key, instance.Tags[key]), h.name)
+		}
+
+		imageName := imageNames[instance.Image]
+		if imageName == "" {
+			imageName = instance.Image
+		}
+		if imageName != "" {
+			addToGroup("image_"+imageName, h.name)
+		}
+		if instance.Package != "" {
+			addToGroup("package_"+instance.Package, h.name)
+		}
+
+		vars := map[string]interface{}{
+			"ansible_host":      h.address,
+			"triton_id":         instance.ID,
+			"triton_name":       instance.Name,
+			"triton_state":      instance.State,
+			"triton_brand":      instance.Brand,
+			"triton_image":      instance.Image,
+			"triton_image_name": imageName,
+			"triton_package":    instance.Package,
+			"triton_primary_ip": instance.PrimaryIP,
+			"triton_ips":        instance.IPs,
+			"triton_networks":   instance.Networks,
+			"triton_dns_names":  instance.DomainNames,
+			"triton_tags":       instance.Tags,
+		}
+		if input.User != "" {
+			vars["ansible_user"] = input.User
+		}
+		if h.proxyJump != "" {
+			vars["ansible_ssh_common_args"] = fmt.Sprintf("-o ProxyJump=%s", proxyJump)
+		}
+		inventory.Meta.HostVars[h.name] = vars
+	}
+
+	return inventory
+}
+
+// ansibleGroupName replaces the characters Ansible does not allow in group
+// names with underscores.
+func ansibleGroupName(name string) string {
+	return ansibleGroupInvalidChars.ReplaceAllString(name, "_")
+}
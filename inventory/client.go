@@ -0,0 +1,27 @@
+package inventory
+
+import (
+	triton "github.com/joyent/triton-go"
+	"github.com/joyent/triton-go/client"
+)
+
+type InventoryClient struct {
+	Client *client.Client
+}
+
+func newInventoryClient(client *client.Client) *InventoryClient {
+	return &InventoryClient{
+		Client: client,
+	}
+}
+
+// NewClient returns a new client for collecting an inventory of the resources
+// of an account across CloudAPI
+func NewClient(config *triton.ClientConfig) (*InventoryClient, error) {
+	client, err := client.NewForUser(config.TritonURL, config.MantaURL, config.AccountName, config.Username, config.Signers...)
+	if err != nil {
+		return nil, err
+	}
+	client.AsRole = config.AsRole
+	return newInventoryClient(client), nil
+}
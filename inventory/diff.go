@@ -0,0 +1,223 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of resources in a snapshot.
+const (
+	KindInstance     = "instance"
+	KindImage        = "image"
+	KindPackage      = "package"
+	KindNetwork      = "network"
+	KindFabricVLAN   = "fabric_vlan"
+	KindFirewallRule = "firewall_rule"
+	KindKey          = "key"
+	KindRole         = "role"
+)
+
+// Actions of a change between two snapshots.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Change is a resource which differs between two snapshots.
+type Change struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+
+	// Fields are the JSON fields of a modified resource which changed.
+	Fields []string `json:"fields,omitempty"`
+}
+
+func (c *Change) String() string {
+	change := fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.ID)
+	if c.Name != "" && c.Name != c.ID {
+		change = fmt.Sprintf("%s (%s)", change, c.Name)
+	}
+	if len(c.Fields) > 0 {
+		change = fmt.Sprintf("%s: %s", change, strings.Join(c.Fields, ", "))
+	}
+	return change
+}
+
+// SnapshotDiff is the drift between two snapshots.
+type SnapshotDiff struct {
+	Changes []*Change `json:"changes"`
+}
+
+// Empty reports whether both snapshots describe the same resources.
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+func (d *SnapshotDiff) String() string {
+	lines := make([]string, 0, len(d.Changes))
+	for _, change := range d.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resource is a resource of a snapshot, identified by its kind and ID.
+type resource struct {
+	id    string
+	name  string
+	value interface{}
+}
+
+// resources returns every resource of the snapshot by kind.
+func (s *Snapshot) resources() map[string][]resource {
+	resources := map[string][]resource{}
+	for _, instance := range s.Instances {
+		resources[KindInstance] = append(resources[KindInstance], resource{instance.ID, instance.Name, instance})
+	}
+	for _, image := range s.Images {
+		resources[KindImage] = append(resources[KindImage], resource{image.ID, image.Name, image})
+	}
+	for _, pkg := range s.Packages {
+		resources[KindPackage] = append(resources[KindPackage], resource{pkg.ID, pkg.Name, pkg})
+	}
+	for _, network := range s.Networks {
+		resources[KindNetwork] = append(resources[KindNetwork], resource{network.Id, network.Name, network})
+	}
+	for _, fabric := range s.Fabrics {
+		if fabric.VLAN == nil {
+			continue
+		}
+		resources[KindFabricVLAN] = append(resources[KindFabricVLAN],
+			resource{strconv.Itoa(fabric.VLAN.ID), fabric.VLAN.Name, fabric})
+	}
+	for _, rule := range s.FirewallRules {
+		resources[KindFirewallRule] = append(resources[KindFirewallRule], resource{rule.ID, rule.Description, rule})
+	}
+	for _, key := range s.Keys {
+		resources[KindKey] = append(resources[KindKey], resource{key.Fingerprint, key.Name, key})
+	}
+	for _, role := range s.Roles {
+		resources[KindRole] = append(resources[KindRole], resource{role.ID, role.Name, role})
+	}
+	return resources
+}
+
+// Diff returns the resources which were added, removed or modified between
+// the from and to snapshots, sorted by kind and ID.
+func Diff(from, to *Snapshot) (*SnapshotDiff, error) {
+	oldResources := from.resources()
+	newResources := to.resources()
+
+	kinds := map[string]bool{}
+	for kind := range oldResources {
+		kinds[kind] = true
+	}
+	for kind := range newResources {
+		kinds[kind] = true
+	}
+
+	diff := &SnapshotDiff{Changes: []*Change{}}
+	for kind := range kinds {
+		oldByID := map[string]resource{}
+		for _, r := range oldResources[kind] {
+			oldByID[r.id] = r
+		}
+		newByID := map[string]resource{}
+		for _, r := range newResources[kind] {
+			newByID[r.id] = r
+		}
+
+		for id, oldResource := range oldByID {
+			newResource, ok := newByID[id]
+			if !ok {
+				diff.Changes = append(diff.Changes, &Change{
+					Kind:   kind,
+					ID:     id,
+					Name:   oldResource.name,
+					Action: ChangeRemoved,
+				})
+				continue
+			}
+
+			fields, err := changedFields(oldResource.value, newResource.value)
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) > 0 {
+				diff.Changes = append(diff.Changes, &Change{
+					Kind:   kind,
+					ID:     id,
+					Name:   newResource.name,
+					Action: ChangeModified,
+					Fields: fields,
+				})
+			}
+		}
+		for id, newResource := range newByID {
+			if _, ok := oldByID[id]; !ok {
+				diff.Changes = append(diff.Changes, &Change{
+					Kind:   kind,
+					ID:     id,
+					Name:   newResource.name,
+					Action: ChangeAdded,
+				})
+			}
+		}
+	}
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+
+	return diff, nil
+}
+
+// changedFields returns the top-level JSON fields which differ between two
+// values of the same type, sorted.
+func changedFields(from, to interface{}) ([]string, error) {
+	oldFields, err := jsonFields(from)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := jsonFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for field, oldValue := range oldFields {
+		if newValue, ok := newFields[field]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
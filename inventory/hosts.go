@@ -0,0 +1,86 @@
+package inventory
+
+import (
+	"sort"
+
+	"github.com/joyent/triton-go/compute"
+	"github.com/joyent/triton-go/network"
+)
+
+// host is an instance reachable over SSH.
+type host struct {
+	name     string
+	address  string
+	instance *compute.Instance
+
+	// proxyJump is the bastion to reach the host through, for hosts which are
+	// only on fabric networks.
+	proxyJump string
+}
+
+// hostsInput holds the parameters shared by every host generator.
+type hostsInput struct {
+	instances      []*compute.Instance
+	networks       []*network.Network
+	bastion        string
+	includeStopped bool
+}
+
+// resolveHosts returns the hosts of instances sorted by name. Instances
+// without an IP address, and stopped instances unless includeStopped is set,
+// are left out. Instances which are only on fabric networks are reached
+// through bastion, unless they are the bastion themselves.
+func resolveHosts(input *hostsInput) []*host {
+	fabrics := map[string]bool{}
+	for _, network := range input.networks {
+		fabrics[network.Id] = network.Fabric
+	}
+
+	var hosts []*host
+	for _, instance := range input.instances {
+		if !input.includeStopped && instance.State != "running" {
+			continue
+		}
+
+		address := instance.PrimaryIP
+		if address == "" && len(instance.IPs) > 0 {
+			address = instance.IPs[0]
+		}
+		if address == "" {
+			continue
+		}
+
+		name := instance.Name
+		if name == "" {
+			name = instance.ID
+		}
+
+		h := &host{
+			name:     name,
+			address:  address,
+			instance: instance,
+		}
+		if input.bastion != "" && name != input.bastion && fabricOnly(instance, fabrics) {
+			h.proxyJump = input.bastion
+		}
+		hosts = append(hosts, h)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].name < hosts[j].name
+	})
+	return hosts
+}
+
+// fabricOnly reports whether every network of instance is a fabric network.
+func fabricOnly(instance *compute.Instance, fabrics map[string]bool) bool {
+	if len(instance.Networks) == 0 {
+		return false
+	}
+	for _, networkID := range instance.Networks {
+		if !fabrics[networkID] {
+			return false
+		}
+	}
+	return true
+}
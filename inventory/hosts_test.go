@@ -0,0 +1,158 @@
+package inventory
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/joyent/triton-go/compute"
+	"github.com/joyent/triton-go/network"
+)
+
+func testHostsInstances() ([]*compute.Instance, []*network.Network) {
+	instances := []*compute.Instance{
+		{
+			ID:        "i1",
+			Name:      "web0",
+			State:     "running",
+			Image:     "img-base",
+			Package:   "g4-highcpu-1G",
+			PrimaryIP: "165.225.1.10",
+			IPs:       []string{"165.225.1.10", "192.168.1.10"},
+			Networks:  []string{"public", "fabric"},
+			Tags:      map[string]interface{}{"role": "web", "env": "prod"},
+		},
+		{
+			ID:        "i2",
+			Name:      "db0",
+			State:     "running",
+			Image:     "img-unknown",
+			Package:   "g4-highcpu-4G",
+			PrimaryIP: "192.168.1.20",
+			IPs:       []string{"192.168.1.20"},
+			Networks:  []string{"fabric"},
+			Tags:      map[string]interface{}{"role": "db"},
+		},
+		{
+			ID:        "i3",
+			Name:      "bastion",
+			State:     "running",
+			PrimaryIP: "165.225.1.2",
+			Networks:  []string{"public"},
+		},
+		{ID: "i4", Name: "old", State: "stopped", PrimaryIP: "165.225.1.3"},
+	}
+	networks := []*network.Network{
+		{Id: "public", Public: true},
+		{Id: "fabric", Fabric: true},
+	}
+	return instances, networks
+}
+
+func TestAnsibleInventoryFor(t *testing.T) {
+	instances, networks := testHostsInstances()
+	inventory := AnsibleInventoryFor(&AnsibleInventoryInput{
+		Instances: instances,
+		Networks:  networks,
+		Images:    []*compute.Image{{ID: "img-base", Name: "base-64"}},
+		Bastion:   "bastion",
+		User:      "root",
+	})
+
+	expectedGroups := map[string][]string{
+		"all":                   {"bastion", "db0", "web0"},
+		"tag_env_prod":          {"web0"},
+		"tag_role_db":           {"db0"},
+		"tag_role_web":          {"web0"},
+		"image_base_64":         {"web0"},
+		"image_img_unknown":     {"db0"},
+		"package_g4_highcpu_1G": {"web0"},
+		"package_g4_highcpu_4G": {"db0"},
+	}
+	if len(inventory.Groups) != len(expectedGroups) {
+		t.Fatalf("unexpected groups: %v", inventory.Groups)
+	}
+	for name, hosts := range expectedGroups {
+		group, ok := inventory.Groups[name]
+		if !ok || !reflect.DeepEqual(group.Hosts, hosts) {
+			t.Fatalf("unexpected hosts of group %s: %v", name, group)
+		}
+	}
+
+	db := inventory.Meta.HostVars["db0"]
+	if db["ansible_host"] != "192.168.1.20" || db["ansible_ssh_common_args"] != "-o ProxyJump=165.225.1.2" {
+		t.Fatalf("unexpected db0 hostvars: %v", db)
+	}
+	web := inventory.Meta.HostVars["web0"]
+	if web["ansible_user"] != "root" || web["ansible_ssh_common_args"] != nil {
+		t.Fatalf("unexpected web0 hostvars: %v", web)
+	}
+
+	encoded, err := json.Marshal(inventory)
+	if err != nil {
+		t.Fatalf("error encoding inventory: %s", err)
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal(encoded, &decoded)
+	if _, ok := decoded["_meta"].(map[string]interface{})["hostvars"]; !ok {
+		t.Fatalf("expected _meta.hostvars in %s", encoded)
+	}
+	if _, ok := decoded["tag_role_web"]; !ok {
+		t.Fatalf("expected groups at the top level in %s", encoded)
+	}
+}
+
+func TestSSHConfigFor(t *testing.T) {
+	instances, networks := testHostsInstances()
+	config := SSHConfigFor(&SSHConfigInput{
+		Instances:    instances,
+		Networks:     networks,
+		Bastion:      "bastion",
+		HostPrefix:   "prod-",
+		User:         "root",
+		IdentityFile: "~/.ssh/triton",
+	})
+
+	expected := `Host prod-bastion
+    HostName 165.225.1.2
+    User root
+    IdentityFile ~/.ssh/triton
+
+Host prod-db0
+    HostName 192.168.1.20
+    User root
+    IdentityFile ~/.ssh/triton
+    ProxyJump prod-bastion
+
+Host prod-web0
+    HostName 165.225.1.10
+    User root
+    IdentityFile ~/.ssh/triton
+`
+	if config != expected {
+		t.Fatalf("unexpected SSH config:\n%s", config)
+	}
+
+	config = SSHConfigFor(&SSHConfigInput{
+		Instances:      instances,
+		Networks:       networks,
+		Bastion:        "root@jump.example.com",
+		IncludeStopped: true,
+	})
+	expected = `Host bastion
+    HostName 165.225.1.2
+
+Host db0
+    HostName 192.168.1.20
+    ProxyJump root@jump.example.com
+
+Host old
+    HostName 165.225.1.3
+
+Host web0
+    HostName 165.225.1.10
+`
+	if config != expected {
+		t.Fatalf("unexpected SSH config:\n%s", config)
+	}
+}
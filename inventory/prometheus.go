@@ -0,0 +1,328 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/compute"
+)
+
+const defaultPrometheusDiscoveryInterval = time.Minute
+
+var prometheusLabelInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// TargetGroup is a group of Prometheus targets sharing the same labels, as
+// read from file_sd files and HTTP SD endpoints.
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// TargetFilter selects the instances which are Prometheus targets, and how
+// they are scraped.
+type TargetFilter struct {
+	// Port is the port scraped on every instance. It may be left out if
+	// CNSService is set and the service declares a port, e.g. "node:9100".
+	Port int
+
+	// Tags are tags which instances must have. An empty value matches any
+	// value of the tag.
+	Tags map[string]string
+
+	// CNSService is a CNS service which instances must belong to.
+	CNSService string
+
+	// PreferPrivateIP scrapes instances on their first private IP rather
+	// than their primary IP.
+	PreferPrivateIP bool
+
+	// IncludeStopped includes instances which are not running.
+	IncludeStopped bool
+}
+
+// PrometheusTargets returns a target group for every instance matching filter,
+// sorted by target. Groups are labelled with the ID, name, image, package and
+// brand of their instance and with its tags as "tag_<key>".
+func PrometheusTargets(instances []*compute.Instance, filter *TargetFilter) []*TargetGroup {
+	groups := []*TargetGroup{}
+	for _, instance := range instances {
+		if !filter.IncludeStopped && instance.State != "running" {
+			continue
+		}
+		if !matchesTags(instance, filter.Tags) {
+			continue
+		}
+
+		port := filter.Port
+		if filter.CNSService != "" {
+			servicePort, ok := cnsServicePort(instance, filter.CNSService)
+			if !ok {
+				continue
+			}
+			if servicePort != 0 {
+				port = servicePort
+			}
+		}
+		if port == 0 {
+			continue
+		}
+
+		address := targetAddress(instance, filter.PreferPrivateIP)
+		if address == "" {
+			continue
+		}
+
+		labels := map[string]string{
+			"triton_instance_id":   instance.ID,
+			"triton_instance_name": instance.Name,
+			"triton_image":         instance.Image,
+			"triton_package":       instance.Package,
+			"triton_brand":         instance.Brand,
+		}
+		for key, value := range instance.Tags {
+			labels["tag_"+prometheusLabelInvalidChars.ReplaceAllString(key, "_")] = fmt.Sprintf("%v", value)
+		}
+
+		groups = append(groups, &TargetGroup{
+			Targets: []string{net.JoinHostPort(address, strconv.Itoa(port))},
+			Labels:  labels,
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Targets[0] < groups[j].Targets[0]
+	})
+	return groups
+}
+
+func matchesTags(instance *compute.Instance, tags map[string]string) bool {
+	for key, want := range tags {
+		value, ok := instance.Tags[key]
+		if !ok {
+			return false
+		}
+		if want != "" && fmt.Sprintf("%v", value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// cnsServicePort reports whether instance belongs to the CNS service, and the
+// port the service declares, if any.
+func cnsServicePort(instance *compute.Instance, service string) (int, bool) {
+	for _, entry := range instance.CNS.Services {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if parts[0] != service {
+			continue
+		}
+		if len(parts) == 2 {
+			if port, err := strconv.Atoi(parts[1]); err == nil {
+				return port, true
+			}
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func targetAddress(instance *compute.Instance, preferPrivate bool) string {
+	if preferPrivate {
+		for _, ip := range instance.IPs {
+			if isPrivateIP(net.ParseIP(ip)) {
+				return ip
+			}
+		}
+	}
+	if instance.PrimaryIP != "" {
+		return instance.PrimaryIP
+	}
+	if len(instance.IPs) > 0 {
+		return instance.IPs[0]
+	}
+	return ""
+}
+
+var privateIPNets = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("fc00::/7"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range privateIPNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrometheusDiscoveryInput represents parameters to a PrometheusDiscovery.
+type PrometheusDiscoveryInput struct {
+	Filter TargetFilter
+
+	// Path is the file_sd file targets are written to. Optional if targets
+	// are only served over HTTP.
+	Path string
+
+	// Interval is the time between refreshes. Defaults to one minute.
+	Interval time.Duration
+
+	// ErrorHandler is called with errors from refreshes made by Run, which
+	// keeps running. Optional.
+	ErrorHandler func(error)
+}
+
+// PrometheusDiscovery periodically lists the instances of the account and
+// publishes them as Prometheus targets, both as a file_sd file and as an HTTP
+// SD endpoint.
+type PrometheusDiscovery struct {
+	input         PrometheusDiscoveryInput
+	listInstances func(context.Context) ([]*compute.Instance, error)
+
+	// refreshMu serializes refreshes, so that concurrent calls do not race
+	// on Path. written is the content last written to Path.
+	refreshMu sync.Mutex
+	written   []byte
+
+	mu      sync.RWMutex
+	targets []byte
+}
+
+// PrometheusDiscovery returns a PrometheusDiscovery of the instances of the
+// account. Call Run to start refreshing it.
+func (c *InventoryClient) PrometheusDiscovery(input *PrometheusDiscoveryInput) *PrometheusDiscovery {
+	return &PrometheusDiscovery{
+		input: *input,
+		listInstances: func(ctx context.Context) ([]*compute.Instance, error) {
+			return (&compute.ComputeClient{Client: c.Client}).Instances().ListAll(ctx, nil)
+		},
+	}
+}
+
+// Refresh lists instances once and updates the targets served over HTTP and,
+// if they changed, written to Path. It reports whether the targets changed. If
+// Path can not be written, the new targets are still served and the write is
+// retried by the next Refresh. Concurrent calls are serialized.
+func (d *PrometheusDiscovery) Refresh(ctx context.Context) (bool, error) {
+	d.refreshMu.Lock()
+	defer d.refreshMu.Unlock()
+
+	instances, err := d.listInstances(ctx)
+	if err != nil {
+		return false, errwrap.Wrapf("Error listing instances for Prometheus discovery: {{err}}", err)
+	}
+
+	targets, err := json.MarshalIndent(PrometheusTargets(instances, &d.input.Filter), "", "  ")
+	if err != nil {
+		return false, errwrap.Wrapf("Error encoding Prometheus targets: {{err}}", err)
+	}
+
+	d.mu.Lock()
+	changed := d.targets == nil || !bytes.Equal(d.targets, targets)
+	d.targets = targets
+	d.mu.Unlock()
+
+	if d.input.Path != "" && (d.written == nil || !bytes.Equal(d.written, targets)) {
+		if err := writeFileAtomic(d.input.Path, targets); err != nil {
+			return changed, errwrap.Wrapf("Error writing Prometheus targets: {{err}}", err)
+		}
+		d.written = targets
+	}
+
+	return changed, nil
+}
+
+// Run refreshes the targets immediately and then every Interval, until ctx is
+// done.
+func (d *PrometheusDiscovery) Run(ctx context.Context) error {
+	interval := d.input.Interval
+	if interval <= 0 {
+		interval = defaultPrometheusDiscoveryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Refresh(ctx); err != nil && d.input.ErrorHandler != nil && ctx.Err() == nil {
+			d.input.ErrorHandler(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler, serving the targets in the format of
+// Prometheus HTTP SD. Until the first refresh, no targets are served.
+func (d *PrometheusDiscovery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.mu.RLock()
+	targets := d.targets
+	d.mu.RUnlock()
+	if targets == nil {
+		targets = []byte("[]")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(targets)
+}
+
+// writeFileAtomic replaces the file at path with data, so that readers never
+// see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,268 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joyent/triton-go/compute"
+)
+
+func testPrometheusInstances() []*compute.Instance {
+	return []*compute.Instance{
+		{
+			ID:        "i1",
+			Name:      "web0",
+			State:     "running",
+			PrimaryIP: "165.225.1.10",
+			IPs:       []string{"165.225.1.10", "192.168.1.10"},
+			Tags:      map[string]interface{}{"role": "web", "monitor.enabled": true},
+			CNS:       compute.InstanceCNS{Services: []string{"web", "node:9100"}},
+		},
+		{
+			ID:        "i2",
+			Name:      "db0",
+			State:     "running",
+			PrimaryIP: "192.168.1.20",
+			Tags:      map[string]interface{}{"role": "db"},
+			CNS:       compute.InstanceCNS{Services: []string{"db"}},
+		},
+		{
+			ID:        "i3",
+			Name:      "web1",
+			State:     "stopped",
+			PrimaryIP: "165.225.1.11",
+			Tags:      map[string]interface{}{"role": "web"},
+		},
+	}
+}
+
+func TestPrometheusTargets(t *testing.T) {
+	instances := testPrometheusInstances()
+
+	groups := PrometheusTargets(instances, &TargetFilter{Port: 8080})
+	if len(groups) != 2 || groups[0].Targets[0] != "165.225.1.10:8080" || groups[1].Targets[0] != "192.168.1.20:8080" {
+		t.Fatalf("unexpected targets: %v", groups)
+	}
+	expectedLabels := map[string]string{
+		"triton_instance_id":   "i1",
+		"triton_instance_name": "web0",
+		"triton_image":         "",
+		"triton_package":       "",
+		"triton_brand":         "",
+		"tag_role":             "web",
+		"tag_monitor_enabled":  "true",
+	}
+	if !reflect.DeepEqual(groups[0].Labels, expectedLabels) {
+		t.Fatalf("unexpected labels: %v", groups[0].Labels)
+	}
+
+	groups = PrometheusTargets(instances, &TargetFilter{
+		Port:            8080,
+		Tags:            map[string]string{"role": "web"},
+		PreferPrivateIP: true,
+		IncludeStopped:  true,
+	})
+	if len(groups) != 2 || groups[0].Targets[0] != "165.225.1.11:8080" || groups[1].Targets[0] != "192.168.1.10:8080" {
+		t.Fatalf("unexpected tag-filtered targets: %v", groups)
+	}
+
+	groups = PrometheusTargets(instances, &TargetFilter{CNSService: "node"})
+	if len(groups) != 1 || groups[0].Targets[0] != "165.225.1.10:9100" {
+		t.Fatalf("unexpected CNS-filtered targets: %v", groups)
+	}
+
+	groups = PrometheusTargets(instances, &TargetFilter{CNSService: "db"})
+	if len(groups) != 0 {
+		t.Fatalf("expected no targets without a port, got %v", groups)
+	}
+}
+
+func TestPrometheusDiscovery_Refresh(t *testing.T) {
+	instances := testPrometheusInstances()
+	path := filepath.Join(t.TempDir(), "triton.json")
+
+	d := &PrometheusDiscovery{
+		input: PrometheusDiscoveryInput{
+			Filter: TargetFilter{Port: 9100},
+			Path:   path,
+		},
+		listInstances: func(context.Context) ([]*compute.Instance, error) {
+			return instances, nil
+		},
+	}
+
+	changed, err := d.Refresh(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("expected first refresh to change targets: %v, %v", changed, err)
+	}
+	var written []*TargetGroup
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading targets file: %s", err)
+	}
+	if err := json.Unmarshal(data, &written); err != nil || len(written) != 2 {
+		t.Fatalf("unexpected targets file: %s", data)
+	}
+
+	changed, err = d.Refresh(context.Background())
+	if err != nil || changed {
+		t.Fatalf("expected unchanged refresh: %v, %v", changed, err)
+	}
+
+	instances = instances[:1]
+	changed, err = d.Refresh(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("expected refresh to change targets: %v, %v", changed, err)
+	}
+	data, _ = ioutil.ReadFile(path)
+	if err := json.Unmarshal(data, &written); err != nil || len(written) != 1 {
+		t.Fatalf("unexpected targets file: %s", data)
+	}
+
+	entries, _ := ioutil.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary files to be left, got %d files", len(entries))
+	}
+}
+
+func TestPrometheusDiscovery_ServeHTTP(t *testing.T) {
+	d := &PrometheusDiscovery{
+		input: PrometheusDiscoveryInput{Filter: TargetFilter{Port: 9100}},
+		listInstances: func(context.Context) ([]*compute.Instance, error) {
+			return testPrometheusInstances(), nil
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	d.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Body.String() != "[]" {
+		t.Fatalf("expected no targets before refresh, got %s", recorder.Body)
+	}
+
+	if _, err := d.Refresh(context.Background()); err != nil {
+		t.Fatalf("error refreshing: %s", err)
+	}
+	recorder = httptest.NewRecorder()
+	d.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	var served []*TargetGroup
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil || len(served) != 2 {
+		t.Fatalf("unexpected served targets: %s", recorder.Body)
+	}
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected content type %q", recorder.Header().Get("Content-Type"))
+	}
+
+	recorder = httptest.NewRecorder()
+	d.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", recorder.Code)
+	}
+}
+
+func TestPrometheusDiscovery_RefreshWriteError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sd")
+	path := filepath.Join(dir, "triton.json")
+
+	d := &PrometheusDiscovery{
+		input: PrometheusDiscoveryInput{
+			Filter: TargetFilter{Port: 9100},
+			Path:   path,
+		},
+		listInstances: func(context.Context) ([]*compute.Instance, error) {
+			return testPrometheusInstances(), nil
+		},
+	}
+
+	// The directory of Path does not exist yet, so the write fails.
+	changed, err := d.Refresh(context.Background())
+	if err == nil || !changed {
+		t.Fatalf("expected a changed refresh with a write error: %v, %v", changed, err)
+	}
+	recorder := httptest.NewRecorder()
+	d.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	var served []*TargetGroup
+	if err := json.Unmarshal(recorder.Body.Bytes(), &served); err != nil || len(served) != 2 {
+		t.Fatalf("expected the new targets to be served, got %s", recorder.Body)
+	}
+
+	// The write is retried even though the targets did not change.
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("error creating directory: %s", err)
+	}
+	changed, err = d.Refresh(context.Background())
+	if err != nil || changed {
+		t.Fatalf("expected an unchanged refresh without error: %v, %v", changed, err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading targets file: %s", err)
+	}
+	if string(data) != recorder.Body.String() {
+		t.Fatalf("expected the served targets to be written, got %s", data)
+	}
+}
+
+func TestPrometheusDiscovery_RefreshConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	d := &PrometheusDiscovery{
+		input: PrometheusDiscoveryInput{
+			Filter: TargetFilter{Port: 9100},
+			Path:   filepath.Join(t.TempDir(), "triton.json"),
+		},
+		listInstances: func(context.Context) ([]*compute.Instance, error) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return testPrometheusInstances(), nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	changes := make(chan bool, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			changed, err := d.Refresh(context.Background())
+			if err != nil {
+				t.Errorf("error refreshing: %s", err)
+			}
+			changes <- changed
+		}()
+	}
+	wg.Wait()
+	close(changes)
+
+	if maxRunning != 1 {
+		t.Fatalf("expected refreshes to be serialized, got %d at once", maxRunning)
+	}
+	count := 0
+	for changed := range changes {
+		if changed {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected exactly one refresh to change the targets, got %d", count)
+	}
+}
@@ -0,0 +1,152 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/account"
+	"github.com/joyent/triton-go/compute"
+	"github.com/joyent/triton-go/identity"
+	"github.com/joyent/triton-go/network"
+)
+
+// SnapshotVersion is the version of the Snapshot format. It is increased
+// whenever the format changes incompatibly.
+const SnapshotVersion = 1
+
+// Snapshot is a point-in-time inventory of the resources of an account.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	Account   string    `json:"account"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Instances     []*compute.Instance           `json:"instances"`
+	Images        []*compute.Image              `json:"images"`
+	Packages      []*compute.Package            `json:"packages"`
+	Networks      []*network.Network            `json:"networks"`
+	Fabrics       []*network.FabricVLANNetworks `json:"fabrics"`
+	FirewallRules []*network.FirewallRule       `json:"firewall_rules"`
+	Keys          []*account.Key                `json:"keys"`
+	Roles         []*identity.Role              `json:"roles"`
+}
+
+// SnapshotInput represents parameters to a Snapshot operation.
+type SnapshotInput struct {
+	// SkipFabrics leaves out fabric VLANs and networks, for datacenters
+	// without fabrics.
+	SkipFabrics bool
+}
+
+// Snapshot collects every resource of the account concurrently. It fails if
+// any resource cannot be listed, rather than returning a partial inventory.
+func (c *InventoryClient) Snapshot(ctx context.Context, input *SnapshotInput) (*Snapshot, error) {
+	if input == nil {
+		input = &SnapshotInput{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		Account:   c.Client.AccountName,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	computeClient := &compute.ComputeClient{Client: c.Client}
+	networkClient := &network.NetworkClient{Client: c.Client}
+	accountClient := &account.AccountClient{Client: c.Client}
+	identityClient := &identity.IdentityClient{Client: c.Client}
+
+	collectors := map[string]func() error{
+		"instances": func() (err error) {
+			snapshot.Instances, err = computeClient.Instances().ListAll(ctx, nil)
+			return err
+		},
+		"images": func() (err error) {
+			snapshot.Images, err = computeClient.Images().List(ctx, &compute.ListImagesInput{})
+			return err
+		},
+		"packages": func() (err error) {
+			snapshot.Packages, err = computeClient.Packages().List(ctx, &compute.ListPackagesInput{})
+			return err
+		},
+		"networks": func() (err error) {
+			snapshot.Networks, err = networkClient.List(ctx, &network.ListInput{})
+			return err
+		},
+		"firewall rules": func() (err error) {
+			snapshot.FirewallRules, err = networkClient.Firewall().ListRules(ctx, &network.ListRulesInput{})
+			return err
+		},
+		"keys": func() (err error) {
+			snapshot.Keys, err = accountClient.Keys().List(ctx, &account.ListKeysInput{})
+			return err
+		},
+		"roles": func() (err error) {
+			snapshot.Roles, err = identityClient.Roles().List(ctx, &identity.ListRolesInput{})
+			return err
+		},
+	}
+	if !input.SkipFabrics {
+		collectors["fabrics"] = func() (err error) {
+			snapshot.Fabrics, err = networkClient.Fabrics().ListAll(ctx, &network.ListAllFabricsInput{})
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for name, collect := range collectors {
+		wg.Add(1)
+		go func(name string, collect func() error) {
+			defer wg.Done()
+			if err := collect(); err != nil {
+				errOnce.Do(func() {
+					firstErr = errwrap.Wrapf(fmt.Sprintf("Error listing %s: {{err}}", name), err)
+					cancel()
+				})
+			}
+		}(name, collect)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, errwrap.Wrapf("Error executing Snapshot request: {{err}}", firstErr)
+	}
+
+	return snapshot, nil
+}
+
+// WriteJSON writes the snapshot as indented JSON.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return errwrap.Wrapf("Error encoding snapshot: {{err}}", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteJSON. Snapshots of another
+// version are rejected.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var snapshot *Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, errwrap.Wrapf("Error decoding snapshot: {{err}}", err)
+	}
+	if snapshot == nil {
+		return nil, errors.New("Error decoding snapshot: empty snapshot")
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("Unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion)
+	}
+	return snapshot, nil
+}
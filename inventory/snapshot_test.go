@@ -0,0 +1,82 @@
+package inventory
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joyent/triton-go/account"
+	"github.com/joyent/triton-go/compute"
+	"github.com/joyent/triton-go/network"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Version:   SnapshotVersion,
+		Account:   "acct",
+		CreatedAt: time.Date(2017, 1, 5, 21, 31, 40, 0, time.UTC),
+		Instances: []*compute.Instance{
+			{ID: "i1", Name: "web0", State: "running", Memory: 1024},
+			{ID: "i2", Name: "db0", State: "running", Memory: 4096},
+		},
+		Networks: []*network.Network{
+			{Id: "n1", Name: "public", Public: true},
+		},
+		Fabrics: []*network.FabricVLANNetworks{
+			{VLAN: &network.FabricVLAN{ID: 2, Name: "internal"}},
+		},
+		Keys: []*account.Key{
+			{Name: "ci", Fingerprint: "aa:bb"},
+		},
+	}
+}
+
+func TestSnapshot_JSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testSnapshot().WriteJSON(&buf); err != nil {
+		t.Fatalf("error writing snapshot: %s", err)
+	}
+
+	snapshot, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("error reading snapshot: %s", err)
+	}
+	diff, err := Diff(testSnapshot(), snapshot)
+	if err != nil {
+		t.Fatalf("error diffing snapshots: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no drift after round trip, got:\n%s", diff)
+	}
+
+	if _, err := ReadSnapshot(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := testSnapshot()
+	to := testSnapshot()
+	to.Instances[0].State = "stopped"
+	to.Instances = to.Instances[:1]
+	to.Instances = append(to.Instances, &compute.Instance{ID: "i3", Name: "web1"})
+	to.Keys = nil
+	to.Fabrics[0].Networks = []*network.Network{{Id: "n2", Name: "internal-net"}}
+
+	diff, err := Diff(from, to)
+	if err != nil {
+		t.Fatalf("error diffing snapshots: %s", err)
+	}
+
+	expected := []string{
+		"modified fabric_vlan 2 (internal): networks",
+		"modified instance i1 (web0): state",
+		"removed instance i2 (db0)",
+		"added instance i3 (web1)",
+		"removed key aa:bb (ci)",
+	}
+	if diff.String() != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+}
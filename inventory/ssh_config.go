@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/joyent/triton-go/compute"
+	"github.com/joyent/triton-go/network"
+)
+
+// SSHConfigInput represents parameters to SSHConfigFor.
+type SSHConfigInput struct {
+	Instances []*compute.Instance
+
+	// Networks are used to find instances which are only on fabric networks.
+	Networks []*network.Network
+
+	// Bastion is the host instances only on fabric networks are reached
+	// through, e.g. "bastion" or "root@bastion.example.com". If it names one
+	// of the instances, that instance is reached directly. Optional.
+	Bastion string
+
+	// HostPrefix is prepended to the name of every host, e.g. "prod-".
+	HostPrefix string
+
+	// User and IdentityFile are set on every host. Optional.
+	User         string
+	IdentityFile string
+
+	// IncludeStopped includes instances which are not running.
+	IncludeStopped bool
+}
+
+// SSHConfigFor returns an ~/.ssh/config snippet with a Host entry for every
+// instance, sorted by name.
+func SSHConfigFor(input *SSHConfigInput) string {
+	hosts := resolveHosts(&hostsInput{
+		instances:      input.Instances,
+		networks:       input.Networks,
+		bastion:        input.Bastion,
+		includeStopped: input.IncludeStopped,
+	})
+
+	var buf bytes.Buffer
+	for i, h := range hosts {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "Host %s%s\n", input.HostPrefix, h.name)
+		fmt.Fprintf(&buf, "    HostName %s\n", h.address)
+		if input.User != "" {
+			fmt.Fprintf(&buf, "    User %s\n", input.User)
+		}
+		if input.IdentityFile != "" {
+			fmt.Fprintf(&buf, "    IdentityFile %s\n", input.IdentityFile)
+		}
+		if h.proxyJump != "" {
+			proxyJump := h.proxyJump
+			if h.proxyJump == input.Bastion && hostNamed(hosts, input.Bastion) {
+				proxyJump = input.HostPrefix + input.Bastion
+			}
+			fmt.Fprintf(&buf, "    ProxyJump %s\n", proxyJump)
+		}
+	}
+	return buf.String()
+}
+
+// hostNamed reports whether one of hosts is named name.
+func hostNamed(hosts []*host, name string) bool {
+	for _, h := range hosts {
+		if h.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -19,10 +19,11 @@ func newNetworkClient(client *client.Client) *NetworkClient {
 // resources within CloudAPI
 func NewClient(config *triton.ClientConfig) (*NetworkClient, error) {
 	// TODO: Utilize config interface within the function itself
-	client, err := client.New(config.TritonURL, config.MantaURL, config.AccountName, config.Signers...)
+	client, err := client.NewForUser(config.TritonURL, config.MantaURL, config.AccountName, config.Username, config.Signers...)
 	if err != nil {
 		return nil, err
 	}
+	client.AsRole = config.AsRole
 	return newNetworkClient(client), nil
 }
 
@@ -15,16 +15,35 @@ type FabricsClient struct {
 	client *client.Client
 }
 
+// defaultFabricName is the name of the fabric every account is given.
+const defaultFabricName = "default"
+
+// fabricPath returns the path of the named fabric, which defaults to the
+// "default" fabric when name is empty.
+func (c *FabricsClient) fabricPath(name string) string {
+	if name == "" {
+		name = defaultFabricName
+	}
+	return fmt.Sprintf("/%s/fabrics/%s", c.client.AccountName, name)
+}
+
 type FabricVLAN struct {
 	Name        string `json:"name"`
 	ID          int    `json:"vlan_id"`
 	Description string `json:"description"`
 }
 
-type ListVLANsInput struct{}
+// ListVLANsInput represents parameters to a ListVLANs operation. Like every
+// fabric input, FabricName selects the fabric and defaults to "default".
+type ListVLANsInput struct {
+	FabricName string `json:"-"`
+}
 
-func (c *FabricsClient) ListVLANs(ctx context.Context, _ *ListVLANsInput) ([]*FabricVLAN, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans", c.client.AccountName)
+func (c *FabricsClient) ListVLANs(ctx context.Context, input *ListVLANsInput) ([]*FabricVLAN, error) {
+	if input == nil {
+		input = &ListVLANsInput{}
+	}
+	path := fmt.Sprintf("%s/vlans", c.fabricPath(input.FabricName))
 	reqInputs := client.RequestInput{
 		Method: http.MethodGet,
 		Path:   path,
@@ -47,13 +66,14 @@ func (c *FabricsClient) ListVLANs(ctx context.Context, _ *ListVLANsInput) ([]*Fa
 }
 
 type CreateVLANInput struct {
+	FabricName  string `json:"-"`
 	Name        string `json:"name"`
 	ID          int    `json:"vlan_id"`
 	Description string `json:"description"`
 }
 
 func (c *FabricsClient) CreateVLAN(ctx context.Context, input *CreateVLANInput) (*FabricVLAN, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans", c.client.AccountName)
+	path := fmt.Sprintf("%s/vlans", c.fabricPath(input.FabricName))
 	reqInputs := client.RequestInput{
 		Method: http.MethodPost,
 		Path:   path,
@@ -77,13 +97,14 @@ func (c *FabricsClient) CreateVLAN(ctx context.Context, input *CreateVLANInput)
 }
 
 type UpdateVLANInput struct {
+	FabricName  string `json:"-"`
 	ID          int    `json:"-"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
 
 func (c *FabricsClient) UpdateVLAN(ctx context.Context, input *UpdateVLANInput) (*FabricVLAN, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d", c.client.AccountName, input.ID)
+	path := fmt.Sprintf("%s/vlans/%d", c.fabricPath(input.FabricName), input.ID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodPut,
 		Path:   path,
@@ -107,11 +128,12 @@ func (c *FabricsClient) UpdateVLAN(ctx context.Context, input *UpdateVLANInput)
 }
 
 type GetVLANInput struct {
-	ID int `json:"-"`
+	FabricName string `json:"-"`
+	ID         int    `json:"-"`
 }
 
 func (c *FabricsClient) GetVLAN(ctx context.Context, input *GetVLANInput) (*FabricVLAN, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d", c.client.AccountName, input.ID)
+	path := fmt.Sprintf("%s/vlans/%d", c.fabricPath(input.FabricName), input.ID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodGet,
 		Path:   path,
@@ -134,11 +156,12 @@ func (c *FabricsClient) GetVLAN(ctx context.Context, input *GetVLANInput) (*Fabr
 }
 
 type DeleteVLANInput struct {
-	ID int `json:"-"`
+	FabricName string `json:"-"`
+	ID         int    `json:"-"`
 }
 
 func (c *FabricsClient) DeleteVLAN(ctx context.Context, input *DeleteVLANInput) error {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d", c.client.AccountName, input.ID)
+	path := fmt.Sprintf("%s/vlans/%d", c.fabricPath(input.FabricName), input.ID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodDelete,
 		Path:   path,
@@ -155,11 +178,12 @@ func (c *FabricsClient) DeleteVLAN(ctx context.Context, input *DeleteVLANInput)
 }
 
 type ListFabricsInput struct {
-	FabricVLANID int `json:"-"`
+	FabricName   string `json:"-"`
+	FabricVLANID int    `json:"-"`
 }
 
 func (c *FabricsClient) List(ctx context.Context, input *ListFabricsInput) ([]*Network, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d/networks", c.client.AccountName, input.FabricVLANID)
+	path := fmt.Sprintf("%s/vlans/%d/networks", c.fabricPath(input.FabricName), input.FabricVLANID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodGet,
 		Path:   path,
@@ -182,6 +206,7 @@ func (c *FabricsClient) List(ctx context.Context, input *ListFabricsInput) ([]*N
 }
 
 type CreateFabricInput struct {
+	FabricName       string            `json:"-"`
 	FabricVLANID     int               `json:"-"`
 	Name             string            `json:"name"`
 	Description      string            `json:"description"`
@@ -195,7 +220,7 @@ type CreateFabricInput struct {
 }
 
 func (c *FabricsClient) Create(ctx context.Context, input *CreateFabricInput) (*Network, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d/networks", c.client.AccountName, input.FabricVLANID)
+	path := fmt.Sprintf("%s/vlans/%d/networks", c.fabricPath(input.FabricName), input.FabricVLANID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodPost,
 		Path:   path,
@@ -219,12 +244,13 @@ func (c *FabricsClient) Create(ctx context.Context, input *CreateFabricInput) (*
 }
 
 type GetFabricInput struct {
+	FabricName   string `json:"-"`
 	FabricVLANID int    `json:"-"`
 	NetworkID    string `json:"-"`
 }
 
 func (c *FabricsClient) Get(ctx context.Context, input *GetFabricInput) (*Network, error) {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d/networks/%s", c.client.AccountName, input.FabricVLANID, input.NetworkID)
+	path := fmt.Sprintf("%s/vlans/%d/networks/%s", c.fabricPath(input.FabricName), input.FabricVLANID, input.NetworkID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodGet,
 		Path:   path,
@@ -247,12 +273,13 @@ func (c *FabricsClient) Get(ctx context.Context, input *GetFabricInput) (*Networ
 }
 
 type DeleteFabricInput struct {
+	FabricName   string `json:"-"`
 	FabricVLANID int    `json:"-"`
 	NetworkID    string `json:"-"`
 }
 
 func (c *FabricsClient) Delete(ctx context.Context, input *DeleteFabricInput) error {
-	path := fmt.Sprintf("/%s/fabrics/default/vlans/%d/networks/%s", c.client.AccountName, input.FabricVLANID, input.NetworkID)
+	path := fmt.Sprintf("%s/vlans/%d/networks/%s", c.fabricPath(input.FabricName), input.FabricVLANID, input.NetworkID)
 	reqInputs := client.RequestInput{
 		Method: http.MethodDelete,
 		Path:   path,
@@ -267,3 +294,107 @@ func (c *FabricsClient) Delete(ctx context.Context, input *DeleteFabricInput) er
 
 	return nil
 }
+
+// UpdateFabricInput represents the fabric network properties to update. Empty
+// strings are left unchanged. Resolvers and Routes are left unchanged when
+// nil; an empty, non-nil value removes every resolver or route.
+type UpdateFabricInput struct {
+	FabricName       string
+	FabricVLANID     int
+	NetworkID        string
+	Name             string
+	Description      string
+	ProvisionStartIP string
+	ProvisionEndIP   string
+	Resolvers        []string
+	Routes           map[string]string
+}
+
+// MarshalJSON implements json.Marshaler, encoding only the properties which
+// are set.
+func (input UpdateFabricInput) MarshalJSON() ([]byte, error) {
+	values := map[string]interface{}{}
+	fields := map[string]string{
+		"name":               input.Name,
+		"description":        input.Description,
+		"provision_start_ip": input.ProvisionStartIP,
+		"provision_end_ip":   input.ProvisionEndIP,
+	}
+	for key, value := range fields {
+		if value != "" {
+			values[key] = value
+		}
+	}
+	if input.Resolvers != nil {
+		values["resolvers"] = input.Resolvers
+	}
+	if input.Routes != nil {
+		values["routes"] = input.Routes
+	}
+	return json.Marshal(values)
+}
+
+// Update changes the name, description, resolvers, routes or provisioning range
+// of a fabric network. See UpdateFabricInput for which fields are sent.
+func (c *FabricsClient) Update(ctx context.Context, input *UpdateFabricInput) (*Network, error) {
+	path := fmt.Sprintf("%s/vlans/%d/networks/%s", c.fabricPath(input.FabricName), input.FabricVLANID, input.NetworkID)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPut,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing UpdateFabric request: {{err}}", err)
+	}
+
+	var result *Network
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding UpdateFabric response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// FabricVLANNetworks is a fabric VLAN together with the networks on it.
+type FabricVLANNetworks struct {
+	VLAN     *FabricVLAN `json:"vlan"`
+	Networks []*Network  `json:"networks"`
+}
+
+type ListAllFabricsInput struct {
+	FabricName string `json:"-"`
+}
+
+// ListAll returns every VLAN of a fabric together with its networks.
+func (c *FabricsClient) ListAll(ctx context.Context, input *ListAllFabricsInput) ([]*FabricVLANNetworks, error) {
+	if input == nil {
+		input = &ListAllFabricsInput{}
+	}
+
+	vlans, err := c.ListVLANs(ctx, &ListVLANsInput{FabricName: input.FabricName})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListAllFabrics request: {{err}}", err)
+	}
+
+	result := make([]*FabricVLANNetworks, 0, len(vlans))
+	for _, vlan := range vlans {
+		networks, err := c.List(ctx, &ListFabricsInput{
+			FabricName:   input.FabricName,
+			FabricVLANID: vlan.ID,
+		})
+		if err != nil {
+			return nil, errwrap.Wrapf("Error executing ListAllFabrics request: {{err}}", err)
+		}
+		result = append(result, &FabricVLANNetworks{
+			VLAN:     vlan,
+			Networks: networks,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestFabricsClient_FabricPath(t *testing.T) {
+	var paths []string
+	c := testNetworkClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`[]`))
+	})
+
+	ctx := context.Background()
+	if _, err := c.Fabrics().ListVLANs(ctx, &ListVLANsInput{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := c.Fabrics().ListVLANs(ctx, &ListVLANsInput{FabricName: "prod"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"/acct/fabrics/default/vlans", "/acct/fabrics/prod/vlans"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected paths %v, got %v", expected, paths)
+	}
+}
+
+func TestFabricsClient_Update(t *testing.T) {
+	cases := []struct {
+		input *UpdateFabricInput
+		body  map[string]interface{}
+	}{
+		{
+			&UpdateFabricInput{Name: "web", Description: "frontend"},
+			map[string]interface{}{"name": "web", "description": "frontend"},
+		},
+		{
+			&UpdateFabricInput{
+				ProvisionStartIP: "10.0.0.10",
+				ProvisionEndIP:   "10.0.0.250",
+				Resolvers:        []string{"8.8.8.8"},
+				Routes:           map[string]string{"10.1.0.0/16": "10.0.0.2"},
+			},
+			map[string]interface{}{
+				"provision_start_ip": "10.0.0.10",
+				"provision_end_ip":   "10.0.0.250",
+				"resolvers":          []interface{}{"8.8.8.8"},
+				"routes":             map[string]interface{}{"10.1.0.0/16": "10.0.0.2"},
+			},
+		},
+		{
+			// Empty, non-nil resolvers and routes clear them.
+			&UpdateFabricInput{Resolvers: []string{}, Routes: map[string]string{}},
+			map[string]interface{}{"resolvers": []interface{}{}, "routes": map[string]interface{}{}},
+		},
+	}
+
+	for _, tc := range cases {
+		var method, path string
+		var body map[string]interface{}
+		c := testNetworkClient(t, func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &body)
+			w.Write([]byte(`{"id": "net-1", "name": "web"}`))
+		})
+
+		input := *tc.input
+		input.FabricName = "prod"
+		input.FabricVLANID = 2
+		input.NetworkID = "net-1"
+		network, err := c.Fabrics().Update(context.Background(), &input)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if network.Id != "net-1" {
+			t.Fatalf("unexpected network %+v", network)
+		}
+		if method != http.MethodPut || path != "/acct/fabrics/prod/vlans/2/networks/net-1" {
+			t.Fatalf("unexpected request %s %s", method, path)
+		}
+		if !reflect.DeepEqual(body, tc.body) {
+			t.Fatalf("expected body %v, got %v", tc.body, body)
+		}
+	}
+}
+
+func TestFabricsClient_ListAll(t *testing.T) {
+	responses := map[string]string{
+		"/acct/fabrics/prod/vlans":            `[{"name": "a", "vlan_id": 2}, {"name": "b", "vlan_id": 3}]`,
+		"/acct/fabrics/prod/vlans/2/networks": `[{"id": "net-2a"}, {"id": "net-2b"}]`,
+		"/acct/fabrics/prod/vlans/3/networks": `[]`,
+	}
+	c := testNetworkClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": "ResourceNotFound", "message": "not found"}`))
+			return
+		}
+		w.Write([]byte(body))
+	})
+
+	fabrics, err := c.Fabrics().ListAll(context.Background(), &ListAllFabricsInput{FabricName: "prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(fabrics) != 2 {
+		t.Fatalf("expected 2 VLANs, got %d", len(fabrics))
+	}
+	if fabrics[0].VLAN.ID != 2 || len(fabrics[0].Networks) != 2 || fabrics[0].Networks[1].Id != "net-2b" {
+		t.Fatalf("unexpected first VLAN %+v", fabrics[0])
+	}
+	if fabrics[1].VLAN.ID != 3 || len(fabrics[1].Networks) != 0 {
+		t.Fatalf("unexpected second VLAN %+v", fabrics[1])
+	}
+
+	// A VLAN whose networks can not be listed fails the whole listing.
+	delete(responses, "/acct/fabrics/prod/vlans/3/networks")
+	if _, err := c.Fabrics().ListAll(context.Background(), &ListAllFabricsInput{FabricName: "prod"}); err == nil {
+		t.Fatal("expected an error listing VLAN 3")
+	}
+}
@@ -0,0 +1,343 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+)
+
+const (
+	RuleChangeCreate  = "create"
+	RuleChangeUpdate  = "update"
+	RuleChangeEnable  = "enable"
+	RuleChangeDisable = "disable"
+	RuleChangeDelete  = "delete"
+)
+
+// ruleChangeOrder is the order in which changes are applied. Rules are created
+// and updated before any are deleted, so a ruleset being replaced is never
+// left without its rules in between.
+var ruleChangeOrder = map[string]int{
+	RuleChangeCreate:  0,
+	RuleChangeUpdate:  1,
+	RuleChangeEnable:  2,
+	RuleChangeDisable: 3,
+	RuleChangeDelete:  4,
+}
+
+// DesiredFirewallRule is a firewall rule as it should exist after
+// reconciliation.
+type DesiredFirewallRule struct {
+	// Key identifies the rule across reconciliations. It must be unique
+	// within a ruleset.
+	Key string
+
+	// Rule is the rule text. It is compared with existing rules in
+	// canonical form, so formatting differences do not cause updates.
+	Rule string
+
+	Enabled bool
+
+	// Description is a human-readable description for the rule. It is only
+	// used when rules are identified by label; otherwise the Key is used as
+	// the description.
+	Description string
+}
+
+// ReconcileRulesInput represents parameters to the PlanRules and ReconcileRules
+// operations.
+type ReconcileRulesInput struct {
+	Rules []*DesiredFirewallRule
+
+	// Label marks the rules managed by this ruleset. If set, managed rules
+	// are stored with a description of the form "[label:key] description",
+	// and any managed rule whose key is not in Rules is deleted.
+	//
+	// If empty, rules are identified by their description, which must equal
+	// the Key of a desired rule. Since any rule could then be claimed, only
+	// rules whose keys are listed in DeleteKeys are deleted.
+	Label string
+
+	// DeleteKeys lists the keys of rules to delete when Label is empty.
+	DeleteKeys []string
+}
+
+// FirewallRuleChange is a single change in a FirewallRulesPlan.
+type FirewallRuleChange struct {
+	// Action is one of the RuleChange constants.
+	Action string
+	Key    string
+
+	// Current is the existing rule, or nil for a create.
+	Current *FirewallRule
+
+	// Desired is the desired rule, or nil for a delete.
+	Desired *DesiredFirewallRule
+
+	// Rule and Description are the rule text and description the rule will
+	// have once the change is applied.
+	Rule        string
+	Description string
+}
+
+func (c *FirewallRuleChange) String() string {
+	switch c.Action {
+	case RuleChangeCreate:
+		return fmt.Sprintf("+ create %s: %s", c.Key, c.Rule)
+	case RuleChangeDelete:
+		return fmt.Sprintf("- delete %s (%s): %s", c.Key, c.Current.ID, c.Current.Rule)
+	case RuleChangeUpdate:
+		return fmt.Sprintf("~ update %s (%s): %s => %s", c.Key, c.Current.ID, c.Current.Rule, c.Rule)
+	default:
+		return fmt.Sprintf("~ %s %s (%s)", c.Action, c.Key, c.Current.ID)
+	}
+}
+
+// FirewallRulesPlan describes the changes needed to bring the firewall rules of
+// an account in line with a desired ruleset.
+type FirewallRulesPlan struct {
+	Changes []*FirewallRuleChange
+
+	// Unchanged lists the keys of managed rules which are already as
+	// desired.
+	Unchanged []string
+
+	// Unmanaged lists the existing rules which are not part of the ruleset
+	// and are left untouched.
+	Unmanaged []*FirewallRule
+}
+
+// Empty reports whether the plan contains no changes.
+func (p *FirewallRulesPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String formats the plan with one change per line.
+func (p *FirewallRulesPlan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+	buf := &bytes.Buffer{}
+	for _, change := range p.Changes {
+		fmt.Fprintln(buf, change)
+	}
+	return buf.String()
+}
+
+// ruleLabelKey returns the key of a rule managed under label, extracted from
+// its description, and whether the rule is managed at all.
+func ruleLabelKey(label, description string) (string, bool) {
+	prefix := "[" + label + ":"
+	if !strings.HasPrefix(description, prefix) {
+		return "", false
+	}
+	end := strings.Index(description, "]")
+	if end < len(prefix) {
+		return "", false
+	}
+	return description[len(prefix):end], true
+}
+
+func ruleLabelDescription(label string, desired *DesiredFirewallRule) string {
+	description := fmt.Sprintf("[%s:%s]", label, desired.Key)
+	if desired.Description != "" {
+		description += " " + desired.Description
+	}
+	return description
+}
+
+// canonicalRuleText returns rule text in canonical form, or unchanged if it
+// can not be parsed.
+func canonicalRuleText(text string) string {
+	canonical, err := FormatRule(text)
+	if err != nil {
+		return strings.TrimSpace(text)
+	}
+	return canonical
+}
+
+// PlanFirewallRules computes the changes needed to turn the current rules into
+// the desired ruleset. It makes no requests. Global rules are never managed.
+func PlanFirewallRules(current []*FirewallRule, input *ReconcileRulesInput) (*FirewallRulesPlan, error) {
+	desiredByKey := make(map[string]*DesiredFirewallRule, len(input.Rules))
+	for _, desired := range input.Rules {
+		if desired.Key == "" {
+			return nil, fmt.Errorf("desired rule %q has no key", desired.Rule)
+		}
+		if input.Label != "" && strings.Contains(desired.Key, "]") {
+			return nil, fmt.Errorf("rule key %q can not contain ]", desired.Key)
+		}
+		if _, ok := desiredByKey[desired.Key]; ok {
+			return nil, fmt.Errorf("duplicate rule key %q", desired.Key)
+		}
+		if _, err := ParseRule(desired.Rule); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error parsing rule %q: {{err}}", desired.Key), err)
+		}
+		desiredByKey[desired.Key] = desired
+	}
+	deleteKeys := make(map[string]bool, len(input.DeleteKeys))
+	for _, key := range input.DeleteKeys {
+		deleteKeys[key] = true
+	}
+
+	plan := &FirewallRulesPlan{}
+	matched := make(map[string]bool, len(desiredByKey))
+	for _, rule := range current {
+		var key string
+		managed := false
+		if !rule.Global {
+			if input.Label != "" {
+				key, managed = ruleLabelKey(input.Label, rule.Description)
+			} else {
+				key = rule.Description
+				managed = desiredByKey[key] != nil || deleteKeys[key]
+			}
+		}
+		if !managed {
+			plan.Unmanaged = append(plan.Unmanaged, rule)
+			continue
+		}
+
+		desired, ok := desiredByKey[key]
+		if !ok || matched[key] {
+			// Rules no longer desired, and duplicates of a rule
+			// already matched, are deleted.
+			if input.Label == "" && !deleteKeys[key] {
+				plan.Unmanaged = append(plan.Unmanaged, rule)
+				continue
+			}
+			plan.Changes = append(plan.Changes, &FirewallRuleChange{
+				Action:      RuleChangeDelete,
+				Key:         key,
+				Current:     rule,
+				Rule:        rule.Rule,
+				Description: rule.Description,
+			})
+			continue
+		}
+		matched[key] = true
+
+		description := desired.Key
+		if input.Label != "" {
+			description = ruleLabelDescription(input.Label, desired)
+		}
+		change := &FirewallRuleChange{
+			Key:         key,
+			Current:     rule,
+			Desired:     desired,
+			Rule:        canonicalRuleText(desired.Rule),
+			Description: description,
+		}
+		switch {
+		case canonicalRuleText(rule.Rule) != change.Rule || rule.Description != description:
+			change.Action = RuleChangeUpdate
+		case rule.Enabled != desired.Enabled && desired.Enabled:
+			change.Action = RuleChangeEnable
+		case rule.Enabled != desired.Enabled:
+			change.Action = RuleChangeDisable
+		default:
+			plan.Unchanged = append(plan.Unchanged, key)
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	for _, desired := range input.Rules {
+		if matched[desired.Key] {
+			continue
+		}
+		description := desired.Key
+		if input.Label != "" {
+			description = ruleLabelDescription(input.Label, desired)
+		}
+		plan.Changes = append(plan.Changes, &FirewallRuleChange{
+			Action:      RuleChangeCreate,
+			Key:         desired.Key,
+			Desired:     desired,
+			Rule:        canonicalRuleText(desired.Rule),
+			Description: description,
+		})
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Action != b.Action {
+			return ruleChangeOrder[a.Action] < ruleChangeOrder[b.Action]
+		}
+		return a.Key < b.Key
+	})
+	sort.Strings(plan.Unchanged)
+
+	return plan, nil
+}
+
+// PlanRules lists the firewall rules of the account and computes the changes
+// needed to reconcile them with the desired ruleset, without applying them.
+func (c *FirewallClient) PlanRules(ctx context.Context, input *ReconcileRulesInput) (*FirewallRulesPlan, error) {
+	current, err := c.ListRules(ctx, &ListRulesInput{})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PlanRules request: {{err}}", err)
+	}
+
+	plan, err := PlanFirewallRules(current, input)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PlanRules request: {{err}}", err)
+	}
+
+	return plan, nil
+}
+
+// ApplyRulesPlan applies the changes of a plan in order, stopping at the first
+// change which fails.
+func (c *FirewallClient) ApplyRulesPlan(ctx context.Context, plan *FirewallRulesPlan) error {
+	for _, change := range plan.Changes {
+		var err error
+		switch change.Action {
+		case RuleChangeCreate:
+			_, err = c.CreateRule(ctx, &CreateRuleInput{
+				Enabled:     change.Desired.Enabled,
+				Rule:        change.Rule,
+				Description: change.Description,
+			})
+		case RuleChangeUpdate:
+			_, err = c.UpdateRule(ctx, &UpdateRuleInput{
+				ID:          change.Current.ID,
+				Enabled:     change.Desired.Enabled,
+				Rule:        change.Rule,
+				Description: change.Description,
+			})
+		case RuleChangeEnable:
+			_, err = c.EnableRule(ctx, &EnableRuleInput{ID: change.Current.ID})
+		case RuleChangeDisable:
+			_, err = c.DisableRule(ctx, &DisableRuleInput{ID: change.Current.ID})
+		case RuleChangeDelete:
+			err = c.DeleteRule(ctx, &DeleteRuleInput{ID: change.Current.ID})
+		default:
+			err = fmt.Errorf("unknown change action %q", change.Action)
+		}
+		if err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error applying %s of rule %q: {{err}}", change.Action, change.Key), err)
+		}
+	}
+
+	return nil
+}
+
+// ReconcileRules brings the firewall rules of the account in line with the
+// desired ruleset and returns the plan that was applied.
+func (c *FirewallClient) ReconcileRules(ctx context.Context, input *ReconcileRulesInput) (*FirewallRulesPlan, error) {
+	plan, err := c.PlanRules(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.ApplyRulesPlan(ctx, plan); err != nil {
+		return plan, errwrap.Wrapf("Error executing ReconcileRules request: {{err}}", err)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,107 @@
+package network
+
+import (
+	"testing"
+)
+
+func TestPlanFirewallRulesLabel(t *testing.T) {
+	current := []*FirewallRule{
+		{ID: "1", Enabled: true, Rule: `FROM any TO tag "www" ALLOW tcp PORT 80`, Description: "[web:http] HTTP"},
+		{ID: "2", Enabled: true, Rule: `FROM any TO tag "www" ALLOW tcp PORT 8443`, Description: "[web:https]"},
+		{ID: "3", Enabled: false, Rule: `FROM any TO tag "www" ALLOW tcp PORT 22`, Description: "[web:ssh]"},
+		{ID: "4", Enabled: true, Rule: `FROM any TO tag "www" ALLOW tcp PORT 8080`, Description: "[web:old]"},
+		{ID: "5", Enabled: true, Rule: `FROM any TO all vms ALLOW tcp PORT 22`, Description: "hand made"},
+		{ID: "6", Enabled: true, Rule: `FROM any TO all vms ALLOW tcp PORT 53`, Description: "[web:http]", Global: true},
+	}
+	input := &ReconcileRulesInput{
+		Label: "web",
+		Rules: []*DesiredFirewallRule{
+			{Key: "http", Rule: `from any to tag www allow tcp port 80`, Enabled: true, Description: "HTTP"},
+			{Key: "https", Rule: `FROM any TO tag "www" ALLOW tcp PORT 443`, Enabled: true},
+			{Key: "ssh", Rule: `FROM any TO tag "www" ALLOW tcp PORT 22`, Enabled: true},
+			{Key: "icmp", Rule: `FROM any TO tag "www" ALLOW icmp TYPE 8`, Enabled: true},
+		},
+	}
+
+	plan, err := PlanFirewallRules(current, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		action string
+		key    string
+	}{
+		{RuleChangeCreate, "icmp"},
+		{RuleChangeUpdate, "https"},
+		{RuleChangeEnable, "ssh"},
+		{RuleChangeDelete, "old"},
+	}
+	if len(plan.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got:\n%s", len(expected), plan)
+	}
+	for i, e := range expected {
+		change := plan.Changes[i]
+		if change.Action != e.action || change.Key != e.key {
+			t.Errorf("change %d: expected %s %s, got %s %s", i, e.action, e.key, change.Action, change.Key)
+		}
+	}
+	if plan.Changes[0].Description != "[web:icmp]" {
+		t.Errorf("unexpected description %q", plan.Changes[0].Description)
+	}
+
+	if len(plan.Unchanged) != 1 || plan.Unchanged[0] != "http" {
+		t.Errorf("expected http unchanged, got %v", plan.Unchanged)
+	}
+	if len(plan.Unmanaged) != 2 {
+		t.Errorf("expected 2 unmanaged rules, got %d", len(plan.Unmanaged))
+	}
+}
+
+func TestPlanFirewallRulesDescription(t *testing.T) {
+	current := []*FirewallRule{
+		{ID: "1", Enabled: true, Rule: `FROM any TO all vms ALLOW tcp PORT 80`, Description: "http"},
+		{ID: "2", Enabled: true, Rule: `FROM any TO all vms ALLOW tcp PORT 21`, Description: "ftp"},
+		{ID: "3", Enabled: true, Rule: `FROM any TO all vms ALLOW tcp PORT 23`, Description: "telnet"},
+	}
+	input := &ReconcileRulesInput{
+		Rules: []*DesiredFirewallRule{
+			{Key: "http", Rule: `FROM any TO all vms ALLOW tcp PORT 80`, Enabled: false},
+		},
+		DeleteKeys: []string{"telnet"},
+	}
+
+	plan, err := PlanFirewallRules(current, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got:\n%s", plan)
+	}
+	if plan.Changes[0].Action != RuleChangeDisable || plan.Changes[0].Key != "http" {
+		t.Errorf("expected disable of http, got %s", plan.Changes[0])
+	}
+	if plan.Changes[1].Action != RuleChangeDelete || plan.Changes[1].Current.ID != "3" {
+		t.Errorf("expected delete of telnet, got %s", plan.Changes[1])
+	}
+	if len(plan.Unmanaged) != 1 || plan.Unmanaged[0].ID != "2" {
+		t.Errorf("expected ftp to be unmanaged, got %v", plan.Unmanaged)
+	}
+}
+
+func TestPlanFirewallRulesErrors(t *testing.T) {
+	cases := []*ReconcileRulesInput{
+		{Rules: []*DesiredFirewallRule{{Rule: `FROM any TO all vms ALLOW tcp PORT 80`}}},
+		{Rules: []*DesiredFirewallRule{{Key: "a", Rule: `FROM any TO all vms ALLOW`}}},
+		{Rules: []*DesiredFirewallRule{
+			{Key: "a", Rule: `FROM any TO all vms ALLOW tcp PORT 80`},
+			{Key: "a", Rule: `FROM any TO all vms ALLOW tcp PORT 81`},
+		}},
+	}
+	for i, input := range cases {
+		if _, err := PlanFirewallRules(nil, input); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
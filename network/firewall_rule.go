@@ -0,0 +1,372 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file models the Triton firewall rule language (FWRULE), e.g.
+//
+//	FROM any TO tag "www" ALLOW tcp PORT 80
+//
+// ParseRule turns rule text into a Rule, which can be validated and formatted
+// back into canonical text without a round trip to CloudAPI. Rules can also be
+// built programmatically and formatted with String.
+
+const (
+	RuleActionAllow = "ALLOW"
+	RuleActionBlock = "BLOCK"
+
+	RuleProtocolTCP   = "tcp"
+	RuleProtocolUDP   = "udp"
+	RuleProtocolICMP  = "icmp"
+	RuleProtocolICMP6 = "icmp6"
+	RuleProtocolAH    = "ah"
+	RuleProtocolESP   = "esp"
+
+	// RuleMaxPriority is the highest priority a rule may be given.
+	RuleMaxPriority = 100
+)
+
+// RuleTargetType is the kind of a RuleTarget.
+type RuleTargetType string
+
+const (
+	RuleTargetAny    RuleTargetType = "any"
+	RuleTargetAllVMs RuleTargetType = "all vms"
+	RuleTargetIP     RuleTargetType = "ip"
+	RuleTargetSubnet RuleTargetType = "subnet"
+	RuleTargetVM     RuleTargetType = "vm"
+	RuleTargetTag    RuleTargetType = "tag"
+)
+
+// RuleTarget is one side, or one alternative of one side, of a rule.
+type RuleTarget struct {
+	Type RuleTargetType
+
+	// Value is the IP address, subnet, instance ID or tag name the target
+	// refers to. It is empty for RuleTargetAny and RuleTargetAllVMs.
+	Value string
+
+	// TagValue restricts a tag target to instances whose tag has this
+	// value. If empty, any instance with the tag matches.
+	TagValue string
+}
+
+// AnyTarget returns a target matching any host.
+func AnyTarget() RuleTarget {
+	return RuleTarget{Type: RuleTargetAny}
+}
+
+// AllVMsTarget returns a target matching every instance in the account.
+func AllVMsTarget() RuleTarget {
+	return RuleTarget{Type: RuleTargetAllVMs}
+}
+
+// IPTarget returns a target matching a single IP address.
+func IPTarget(ip string) RuleTarget {
+	return RuleTarget{Type: RuleTargetIP, Value: ip}
+}
+
+// SubnetTarget returns a target matching every address in a CIDR subnet.
+func SubnetTarget(cidr string) RuleTarget {
+	return RuleTarget{Type: RuleTargetSubnet, Value: cidr}
+}
+
+// VMTarget returns a target matching a single instance.
+func VMTarget(instanceID string) RuleTarget {
+	return RuleTarget{Type: RuleTargetVM, Value: instanceID}
+}
+
+// TagTarget returns a target matching instances with the given tag. If a value
+// is given, only instances whose tag has that value match.
+func TagTarget(name string, value ...string) RuleTarget {
+	target := RuleTarget{Type: RuleTargetTag, Value: name}
+	if len(value) > 0 {
+		target.TagValue = value[0]
+	}
+	return target
+}
+
+// selectsVMs reports whether a target selects instances by identity rather
+// than by address.
+func (t RuleTarget) selectsVMs() bool {
+	return t.Type == RuleTargetAllVMs || t.Type == RuleTargetVM || t.Type == RuleTargetTag
+}
+
+// String formats the target in canonical form.
+func (t RuleTarget) String() string {
+	switch t.Type {
+	case RuleTargetAny, RuleTargetAllVMs:
+		return string(t.Type)
+	case RuleTargetTag:
+		if t.TagValue != "" {
+			return fmt.Sprintf("tag %s = %s", quoteRuleString(t.Value), quoteRuleString(t.TagValue))
+		}
+		return fmt.Sprintf("tag %s", quoteRuleString(t.Value))
+	default:
+		return fmt.Sprintf("%s %s", t.Type, t.Value)
+	}
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate checks the target is well formed.
+func (t RuleTarget) Validate() error {
+	switch t.Type {
+	case RuleTargetAny, RuleTargetAllVMs:
+		if t.Value != "" || t.TagValue != "" {
+			return fmt.Errorf("target %q takes no value", t.Type)
+		}
+	case RuleTargetIP:
+		if net.ParseIP(t.Value) == nil {
+			return fmt.Errorf("invalid IP address %q", t.Value)
+		}
+	case RuleTargetSubnet:
+		ip, subnet, err := net.ParseCIDR(t.Value)
+		if err != nil {
+			return fmt.Errorf("invalid subnet %q", t.Value)
+		}
+		if !ip.Equal(subnet.IP) {
+			return fmt.Errorf("subnet %q is not a network address, use %q", t.Value, subnet.String())
+		}
+	case RuleTargetVM:
+		if !uuidRegexp.MatchString(t.Value) {
+			return fmt.Errorf("invalid instance ID %q", t.Value)
+		}
+	case RuleTargetTag:
+		if t.Value == "" {
+			return errors.New("tag name can not be empty")
+		}
+	default:
+		return fmt.Errorf("unknown target type %q", t.Type)
+	}
+	return nil
+}
+
+// RulePortRange is an inclusive range of ports. A single port has Start equal
+// to End.
+type RulePortRange struct {
+	Start int
+	End   int
+}
+
+// RulePort returns a range consisting of a single port.
+func RulePort(port int) RulePortRange {
+	return RulePortRange{Start: port, End: port}
+}
+
+// String formats the range in canonical form.
+func (p RulePortRange) String() string {
+	if p.Start == p.End {
+		return strconv.Itoa(p.Start)
+	}
+	return fmt.Sprintf("%d - %d", p.Start, p.End)
+}
+
+// RuleICMPType is an ICMP type, optionally restricted to a single code.
+type RuleICMPType struct {
+	Type    int
+	Code    int
+	HasCode bool
+}
+
+// String formats the ICMP type in canonical form.
+func (t RuleICMPType) String() string {
+	if t.HasCode {
+		return fmt.Sprintf("TYPE %d CODE %d", t.Type, t.Code)
+	}
+	return fmt.Sprintf("TYPE %d", t.Type)
+}
+
+// Rule is a parsed firewall rule.
+type Rule struct {
+	From []RuleTarget
+	To   []RuleTarget
+
+	// Action is RuleActionAllow or RuleActionBlock.
+	Action string
+
+	// Protocol is one of the RuleProtocol constants.
+	Protocol string
+
+	// AllPorts matches every port for tcp and udp, or every type for icmp
+	// and icmp6 ("PORT all" and "TYPE all").
+	AllPorts bool
+
+	// Ports lists the ports matched by tcp and udp rules.
+	Ports []RulePortRange
+
+	// ICMPTypes lists the types matched by icmp and icmp6 rules.
+	ICMPTypes []RuleICMPType
+
+	// Priority orders conflicting rules; higher priorities win. Zero is the
+	// default and is omitted from the canonical form.
+	Priority int
+}
+
+// String formats the rule in canonical form. The result of formatting a valid
+// rule can always be parsed back into an equivalent Rule.
+func (r *Rule) String() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("FROM ")
+	writeRuleTargets(buf, r.From)
+	buf.WriteString(" TO ")
+	writeRuleTargets(buf, r.To)
+	fmt.Fprintf(buf, " %s %s", strings.ToUpper(r.Action), strings.ToLower(r.Protocol))
+
+	switch r.Protocol {
+	case RuleProtocolTCP, RuleProtocolUDP:
+		switch {
+		case r.AllPorts:
+			buf.WriteString(" PORT all")
+		case len(r.Ports) == 1 && r.Ports[0].Start == r.Ports[0].End:
+			fmt.Fprintf(buf, " PORT %d", r.Ports[0].Start)
+		case len(r.Ports) > 0:
+			ports := make([]string, 0, len(r.Ports))
+			for _, port := range r.Ports {
+				ports = append(ports, port.String())
+			}
+			fmt.Fprintf(buf, " PORTS %s", strings.Join(ports, ", "))
+		}
+	case RuleProtocolICMP, RuleProtocolICMP6:
+		switch {
+		case r.AllPorts:
+			buf.WriteString(" TYPE all")
+		case len(r.ICMPTypes) == 1:
+			fmt.Fprintf(buf, " %s", r.ICMPTypes[0])
+		case len(r.ICMPTypes) > 1:
+			types := make([]string, 0, len(r.ICMPTypes))
+			for _, icmpType := range r.ICMPTypes {
+				types = append(types, icmpType.String())
+			}
+			fmt.Fprintf(buf, " (%s)", strings.Join(types, " AND "))
+		}
+	}
+
+	if r.Priority != 0 {
+		fmt.Fprintf(buf, " PRIORITY %d", r.Priority)
+	}
+
+	return buf.String()
+}
+
+func writeRuleTargets(buf *bytes.Buffer, targets []RuleTarget) {
+	if len(targets) == 1 {
+		buf.WriteString(targets[0].String())
+		return
+	}
+	parts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		parts = append(parts, target.String())
+	}
+	fmt.Fprintf(buf, "(%s)", strings.Join(parts, " OR "))
+}
+
+// Validate checks the rule is one CloudAPI would accept.
+func (r *Rule) Validate() error {
+	if len(r.From) == 0 {
+		return errors.New("rule has no FROM targets")
+	}
+	if len(r.To) == 0 {
+		return errors.New("rule has no TO targets")
+	}
+
+	selectsVMs := false
+	for _, side := range [][]RuleTarget{r.From, r.To} {
+		for _, target := range side {
+			if err := target.Validate(); err != nil {
+				return err
+			}
+			if target.selectsVMs() {
+				selectsVMs = true
+			}
+		}
+	}
+	if !selectsVMs {
+		return errors.New("rule must select instances with vm, tag or all vms on at least one side")
+	}
+
+	switch r.Action {
+	case RuleActionAllow, RuleActionBlock:
+	default:
+		return fmt.Errorf("invalid action %q", r.Action)
+	}
+
+	switch r.Protocol {
+	case RuleProtocolTCP, RuleProtocolUDP:
+		if len(r.ICMPTypes) > 0 {
+			return fmt.Errorf("protocol %s does not take ICMP types", r.Protocol)
+		}
+		if r.AllPorts && len(r.Ports) > 0 {
+			return errors.New("rule can not match all ports and specific ports")
+		}
+		if !r.AllPorts && len(r.Ports) == 0 {
+			return fmt.Errorf("protocol %s requires ports", r.Protocol)
+		}
+		for _, port := range r.Ports {
+			if port.Start < 1 || port.End > 65535 {
+				return fmt.Errorf("port %s out of range 1-65535", port)
+			}
+			if port.Start > port.End {
+				return fmt.Errorf("port range %s is reversed", port)
+			}
+		}
+	case RuleProtocolICMP, RuleProtocolICMP6:
+		if len(r.Ports) > 0 {
+			return fmt.Errorf("protocol %s does not take ports", r.Protocol)
+		}
+		if r.AllPorts && len(r.ICMPTypes) > 0 {
+			return errors.New("rule can not match all types and specific types")
+		}
+		if !r.AllPorts && len(r.ICMPTypes) == 0 {
+			return fmt.Errorf("protocol %s requires ICMP types", r.Protocol)
+		}
+		for _, icmpType := range r.ICMPTypes {
+			if icmpType.Type < 0 || icmpType.Type > 255 {
+				return fmt.Errorf("ICMP type %d out of range 0-255", icmpType.Type)
+			}
+			if icmpType.HasCode && (icmpType.Code < 0 || icmpType.Code > 255) {
+				return fmt.Errorf("ICMP code %d out of range 0-255", icmpType.Code)
+			}
+		}
+	case RuleProtocolAH, RuleProtocolESP:
+		if r.AllPorts || len(r.Ports) > 0 || len(r.ICMPTypes) > 0 {
+			return fmt.Errorf("protocol %s does not take ports or types", r.Protocol)
+		}
+	default:
+		return fmt.Errorf("unknown protocol %q", r.Protocol)
+	}
+
+	if r.Priority < 0 || r.Priority > RuleMaxPriority {
+		return fmt.Errorf("priority %d out of range 0-%d", r.Priority, RuleMaxPriority)
+	}
+
+	return nil
+}
+
+// ValidateRule parses and validates rule text, returning the first problem
+// found.
+func ValidateRule(text string) error {
+	_, err := ParseRule(text)
+	return err
+}
+
+// FormatRule parses rule text and returns it in canonical form.
+func FormatRule(text string) (string, error) {
+	rule, err := ParseRule(text)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// Parse parses the text of a firewall rule returned by CloudAPI.
+func (r *FirewallRule) Parse() (*Rule, error) {
+	return ParseRule(r.Rule)
+}
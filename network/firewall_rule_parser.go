@@ -0,0 +1,474 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RuleSyntaxError is returned by ParseRule when rule text can not be parsed.
+type RuleSyntaxError struct {
+	// Offset is the byte offset into the rule text at which the error was
+	// detected.
+	Offset  int
+	Message string
+}
+
+func (e *RuleSyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Message)
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokenEOF ruleTokenKind = iota
+	ruleTokenWord
+	ruleTokenString
+	ruleTokenLParen
+	ruleTokenRParen
+	ruleTokenComma
+	ruleTokenEquals
+	ruleTokenDash
+)
+
+type ruleToken struct {
+	kind   ruleTokenKind
+	text   string
+	offset int
+}
+
+func (t ruleToken) describe() string {
+	switch t.kind {
+	case ruleTokenEOF:
+		return "end of rule"
+	case ruleTokenString:
+		return quoteRuleString(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// quoteRuleString double-quotes a string the way tokenizeRule reads it back:
+// only quotes and backslashes are escaped, with a backslash, and every other
+// character is written as is.
+func quoteRuleString(s string) string {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(s[i])
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func isRuleWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._:/-", r)
+}
+
+func tokenizeRule(text string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(text)
+	offsets := make([]int, 0, len(runes)+1)
+	for offset := range text {
+		offsets = append(offsets, offset)
+	}
+	offsets = append(offsets, len(text))
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, ruleToken{ruleTokenLParen, "(", offsets[i]})
+			i++
+		case r == ')':
+			tokens = append(tokens, ruleToken{ruleTokenRParen, ")", offsets[i]})
+			i++
+		case r == ',':
+			tokens = append(tokens, ruleToken{ruleTokenComma, ",", offsets[i]})
+			i++
+		case r == '=':
+			tokens = append(tokens, ruleToken{ruleTokenEquals, "=", offsets[i]})
+			i++
+		case r == '"':
+			start := i
+			i++
+			value := []rune{}
+			for {
+				if i >= len(runes) {
+					return nil, &RuleSyntaxError{offsets[start], "unterminated string"}
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value = append(value, runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				value = append(value, runes[i])
+				i++
+			}
+			tokens = append(tokens, ruleToken{ruleTokenString, string(value), offsets[start]})
+		case isRuleWordChar(r):
+			start := i
+			for i < len(runes) && isRuleWordChar(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			kind := ruleTokenWord
+			if word == "-" {
+				kind = ruleTokenDash
+			}
+			tokens = append(tokens, ruleToken{kind, word, offsets[start]})
+		default:
+			return nil, &RuleSyntaxError{offsets[i], fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	tokens = append(tokens, ruleToken{ruleTokenEOF, "", len(text)})
+	return tokens, nil
+}
+
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ruleParser) next() ruleToken {
+	token := p.tokens[p.pos]
+	if token.kind != ruleTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *ruleParser) errorf(token ruleToken, format string, args ...interface{}) error {
+	return &RuleSyntaxError{token.offset, fmt.Sprintf(format, args...)}
+}
+
+// isKeyword reports whether token is the given keyword, ignoring case.
+func (p *ruleParser) isKeyword(token ruleToken, keyword string) bool {
+	return token.kind == ruleTokenWord && strings.EqualFold(token.text, keyword)
+}
+
+func (p *ruleParser) expectKeyword(keyword string) error {
+	token := p.next()
+	if !p.isKeyword(token, keyword) {
+		return p.errorf(token, "expected %s, found %s", keyword, token.describe())
+	}
+	return nil
+}
+
+func (p *ruleParser) expect(kind ruleTokenKind, what string) error {
+	token := p.next()
+	if token.kind != kind {
+		return p.errorf(token, "expected %s, found %s", what, token.describe())
+	}
+	return nil
+}
+
+func (p *ruleParser) parseInt(what string) (int, error) {
+	token := p.next()
+	if token.kind != ruleTokenWord {
+		return 0, p.errorf(token, "expected %s, found %s", what, token.describe())
+	}
+	value, err := strconv.Atoi(token.text)
+	if err != nil {
+		return 0, p.errorf(token, "expected %s, found %s", what, token.describe())
+	}
+	return value, nil
+}
+
+// ParseRule parses rule text written in the Triton firewall rule language and
+// validates the result. Keywords are matched case-insensitively.
+func ParseRule(text string) (*Rule, error) {
+	tokens, err := tokenizeRule(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+
+	rule := &Rule{}
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if rule.From, err = p.parseTargets(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("TO"); err != nil {
+		return nil, err
+	}
+	if rule.To, err = p.parseTargets(); err != nil {
+		return nil, err
+	}
+
+	token := p.next()
+	switch {
+	case p.isKeyword(token, RuleActionAllow):
+		rule.Action = RuleActionAllow
+	case p.isKeyword(token, RuleActionBlock):
+		rule.Action = RuleActionBlock
+	default:
+		return nil, p.errorf(token, "expected ALLOW or BLOCK, found %s", token.describe())
+	}
+
+	token = p.next()
+	if token.kind != ruleTokenWord {
+		return nil, p.errorf(token, "expected protocol, found %s", token.describe())
+	}
+	rule.Protocol = strings.ToLower(token.text)
+	switch rule.Protocol {
+	case RuleProtocolTCP, RuleProtocolUDP:
+		if err := p.parsePorts(rule); err != nil {
+			return nil, err
+		}
+	case RuleProtocolICMP, RuleProtocolICMP6:
+		if err := p.parseICMPTypes(rule); err != nil {
+			return nil, err
+		}
+	case RuleProtocolAH, RuleProtocolESP:
+	default:
+		return nil, p.errorf(token, "unknown protocol %s", token.describe())
+	}
+
+	if p.isKeyword(p.peek(), "PRIORITY") {
+		p.next()
+		if rule.Priority, err = p.parseInt("priority"); err != nil {
+			return nil, err
+		}
+	}
+
+	if token := p.next(); token.kind != ruleTokenEOF {
+		return nil, p.errorf(token, "unexpected %s", token.describe())
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (p *ruleParser) parseTargets() ([]RuleTarget, error) {
+	if p.peek().kind != ruleTokenLParen {
+		target, err := p.parseTarget()
+		if err != nil {
+			return nil, err
+		}
+		return []RuleTarget{target}, nil
+	}
+
+	p.next()
+	var targets []RuleTarget
+	for {
+		target, err := p.parseTarget()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+
+		if !p.isKeyword(p.peek(), "OR") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(ruleTokenRParen, "OR or )"); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+func (p *ruleParser) parseTarget() (RuleTarget, error) {
+	token := p.next()
+	if token.kind != ruleTokenWord {
+		return RuleTarget{}, p.errorf(token, "expected target, found %s", token.describe())
+	}
+
+	switch strings.ToLower(token.text) {
+	case "any":
+		return AnyTarget(), nil
+	case "all":
+		if err := p.expectKeyword("vms"); err != nil {
+			return RuleTarget{}, err
+		}
+		return AllVMsTarget(), nil
+	case "ip", "subnet", "vm":
+		value := p.next()
+		if value.kind != ruleTokenWord {
+			return RuleTarget{}, p.errorf(value, "expected %s value, found %s", strings.ToLower(token.text), value.describe())
+		}
+		return RuleTarget{Type: RuleTargetType(strings.ToLower(token.text)), Value: value.text}, nil
+	case "tag":
+		name, err := p.parseTagText("tag name")
+		if err != nil {
+			return RuleTarget{}, err
+		}
+		if p.peek().kind != ruleTokenEquals {
+			return TagTarget(name), nil
+		}
+		p.next()
+		value, err := p.parseTagText("tag value")
+		if err != nil {
+			return RuleTarget{}, err
+		}
+		return TagTarget(name, value), nil
+	default:
+		return RuleTarget{}, p.errorf(token, "expected target, found %s", token.describe())
+	}
+}
+
+func (p *ruleParser) parseTagText(what string) (string, error) {
+	token := p.next()
+	if token.kind != ruleTokenWord && token.kind != ruleTokenString {
+		return "", p.errorf(token, "expected %s, found %s", what, token.describe())
+	}
+	return token.text, nil
+}
+
+func (p *ruleParser) parsePorts(rule *Rule) error {
+	token := p.next()
+	switch {
+	case p.isKeyword(token, "PORT"):
+		if p.isKeyword(p.peek(), "all") {
+			p.next()
+			rule.AllPorts = true
+			return nil
+		}
+		port, err := p.parseInt("port")
+		if err != nil {
+			return err
+		}
+		rule.Ports = []RulePortRange{RulePort(port)}
+		return nil
+	case p.isKeyword(token, "PORTS"):
+		for {
+			portRange, err := p.parsePortRange()
+			if err != nil {
+				return err
+			}
+			rule.Ports = append(rule.Ports, portRange)
+
+			if p.peek().kind != ruleTokenComma {
+				return nil
+			}
+			p.next()
+		}
+	case token.kind == ruleTokenLParen:
+		for {
+			if err := p.expectKeyword("PORT"); err != nil {
+				return err
+			}
+			port, err := p.parseInt("port")
+			if err != nil {
+				return err
+			}
+			rule.Ports = append(rule.Ports, RulePort(port))
+
+			if !p.isKeyword(p.peek(), "AND") {
+				break
+			}
+			p.next()
+		}
+		return p.expect(ruleTokenRParen, "AND or )")
+	default:
+		return p.errorf(token, "expected PORT or PORTS, found %s", token.describe())
+	}
+}
+
+// parsePortRange parses a port or a range of ports, written either as
+// "1000 - 2000" or "1000-2000".
+func (p *ruleParser) parsePortRange() (RulePortRange, error) {
+	token := p.peek()
+	if token.kind == ruleTokenWord && strings.Contains(token.text, "-") {
+		p.next()
+		parts := strings.SplitN(token.text, "-", 2)
+		start, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return RulePortRange{}, p.errorf(token, "invalid port range %s", token.describe())
+		}
+		end, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return RulePortRange{}, p.errorf(token, "invalid port range %s", token.describe())
+		}
+		return RulePortRange{Start: start, End: end}, nil
+	}
+
+	start, err := p.parseInt("port")
+	if err != nil {
+		return RulePortRange{}, err
+	}
+	if p.peek().kind != ruleTokenDash {
+		return RulePort(start), nil
+	}
+	p.next()
+	end, err := p.parseInt("end of port range")
+	if err != nil {
+		return RulePortRange{}, err
+	}
+	return RulePortRange{Start: start, End: end}, nil
+}
+
+func (p *ruleParser) parseICMPTypes(rule *Rule) error {
+	if p.peek().kind != ruleTokenLParen {
+		if err := p.expectKeyword("TYPE"); err != nil {
+			return err
+		}
+		if p.isKeyword(p.peek(), "all") {
+			p.next()
+			rule.AllPorts = true
+			return nil
+		}
+		icmpType, err := p.parseICMPType()
+		if err != nil {
+			return err
+		}
+		rule.ICMPTypes = []RuleICMPType{icmpType}
+		return nil
+	}
+
+	p.next()
+	for {
+		if err := p.expectKeyword("TYPE"); err != nil {
+			return err
+		}
+		icmpType, err := p.parseICMPType()
+		if err != nil {
+			return err
+		}
+		rule.ICMPTypes = append(rule.ICMPTypes, icmpType)
+
+		if !p.isKeyword(p.peek(), "AND") {
+			break
+		}
+		p.next()
+	}
+	return p.expect(ruleTokenRParen, "AND or )")
+}
+
+// parseICMPType parses the number following TYPE and an optional CODE.
+func (p *ruleParser) parseICMPType() (RuleICMPType, error) {
+	icmpType := RuleICMPType{}
+	var err error
+	if icmpType.Type, err = p.parseInt("ICMP type"); err != nil {
+		return icmpType, err
+	}
+	if p.isKeyword(p.peek(), "CODE") {
+		p.next()
+		if icmpType.Code, err = p.parseInt("ICMP code"); err != nil {
+			return icmpType, err
+		}
+		icmpType.HasCode = true
+	}
+	return icmpType, nil
+}
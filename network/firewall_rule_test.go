@@ -0,0 +1,158 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRuleCanonical(t *testing.T) {
+	cases := []struct {
+		text      string
+		canonical string
+	}{
+		{
+			`FROM any TO tag "www" ALLOW tcp PORT 80`,
+			`FROM any TO tag "www" ALLOW tcp PORT 80`,
+		},
+		{
+			`from any to tag www allow TCP port 80`,
+			`FROM any TO tag "www" ALLOW tcp PORT 80`,
+		},
+		{
+			`FROM (ip 10.0.0.1 OR subnet 10.1.0.0/16) TO all vms BLOCK udp PORTS 53, 1000-2000 PRIORITY 10`,
+			`FROM (ip 10.0.0.1 OR subnet 10.1.0.0/16) TO all vms BLOCK udp PORTS 53, 1000 - 2000 PRIORITY 10`,
+		},
+		{
+			`FROM all vms TO tag role = "db" ALLOW tcp (PORT 5432 AND PORT 6432)`,
+			`FROM all vms TO tag "role" = "db" ALLOW tcp PORTS 5432, 6432`,
+		},
+		{
+			`FROM any TO vm 6ac2a9a8-bd9e-4a3b-8a4c-6b35a8b1f2b0 ALLOW icmp TYPE 8 CODE 0`,
+			`FROM any TO vm 6ac2a9a8-bd9e-4a3b-8a4c-6b35a8b1f2b0 ALLOW icmp TYPE 8 CODE 0`,
+		},
+		{
+			`FROM any TO all vms ALLOW icmp6 (TYPE 128 AND TYPE 129)`,
+			`FROM any TO all vms ALLOW icmp6 (TYPE 128 AND TYPE 129)`,
+		},
+		{
+			`FROM all vms TO any ALLOW tcp PORT all`,
+			`FROM all vms TO any ALLOW tcp PORT all`,
+		},
+		{
+			`FROM tag "a b" TO ip fd00::1 ALLOW esp`,
+			`FROM tag "a b" TO ip fd00::1 ALLOW esp`,
+		},
+	}
+
+	for _, c := range cases {
+		rule, err := ParseRule(c.text)
+		if err != nil {
+			t.Errorf("ParseRule(%q): %s", c.text, err)
+			continue
+		}
+		if got := rule.String(); got != c.canonical {
+			t.Errorf("ParseRule(%q).String() = %q, want %q", c.text, got, c.canonical)
+		}
+
+		reparsed, err := ParseRule(rule.String())
+		if err != nil {
+			t.Errorf("ParseRule(%q): %s", rule.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(rule, reparsed) {
+			t.Errorf("round trip of %q changed the rule: %+v != %+v", c.text, rule, reparsed)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	cases := []string{
+		``,
+		`FROM any TO any ALLOW tcp PORT 80`,
+		`FROM any TO all vms ALLOW tcp`,
+		`FROM any TO all vms ALLOW tcp PORT 70000`,
+		`FROM any TO all vms ALLOW tcp PORTS 2000 - 1000`,
+		`FROM any TO all vms ALLOW sctp PORT 80`,
+		`FROM any TO all vms PERMIT tcp PORT 80`,
+		`FROM ip 10.0.0.256 TO all vms ALLOW tcp PORT 80`,
+		`FROM subnet 10.0.0.1/24 TO all vms ALLOW tcp PORT 80`,
+		`FROM any TO vm web01 ALLOW tcp PORT 80`,
+		`FROM (any OR all vms TO all vms ALLOW tcp PORT 80`,
+		`FROM any TO tag "www ALLOW tcp PORT 80`,
+		`FROM any TO all vms ALLOW tcp PORT 80 PRIORITY 101`,
+		`FROM any TO all vms ALLOW tcp PORT 80 extra`,
+		`FROM any TO all vms ALLOW esp PORT 80`,
+	}
+
+	for _, text := range cases {
+		if _, err := ParseRule(text); err == nil {
+			t.Errorf("ParseRule(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestParseRuleSyntaxErrorOffset(t *testing.T) {
+	_, err := ParseRule(`FROM any TO all vms PERMIT tcp PORT 80`)
+	syntaxErr, ok := err.(*RuleSyntaxError)
+	if !ok {
+		t.Fatalf("expected *RuleSyntaxError, got %#v", err)
+	}
+	if syntaxErr.Offset != 20 {
+		t.Errorf("expected offset 20, got %d", syntaxErr.Offset)
+	}
+}
+
+func TestRuleBuild(t *testing.T) {
+	rule := &Rule{
+		From:     []RuleTarget{AnyTarget()},
+		To:       []RuleTarget{TagTarget("www"), TagTarget("role", "api")},
+		Action:   RuleActionAllow,
+		Protocol: RuleProtocolTCP,
+		Ports:    []RulePortRange{RulePort(443)},
+	}
+	if err := rule.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `FROM any TO (tag "www" OR tag "role" = "api") ALLOW tcp PORT 443`
+	if got := rule.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestRuleTagValueRoundTrip(t *testing.T) {
+	values := []string{
+		"tab\there",
+		"line\nbreak",
+		"café",
+		`quote " and backslash \`,
+	}
+
+	for _, value := range values {
+		rule := &Rule{
+			From:     []RuleTarget{AnyTarget()},
+			To:       []RuleTarget{TagTarget("role", value)},
+			Action:   RuleActionAllow,
+			Protocol: RuleProtocolTCP,
+			Ports:    []RulePortRange{RulePort(443)},
+		}
+
+		formatted, err := FormatRule(rule.String())
+		if err != nil {
+			t.Errorf("FormatRule(%q): %s", rule.String(), err)
+			continue
+		}
+		if formatted != rule.String() {
+			t.Errorf("FormatRule(%q) = %q, expected it unchanged", rule.String(), formatted)
+		}
+
+		reparsed, err := ParseRule(formatted)
+		if err != nil {
+			t.Errorf("ParseRule(%q): %s", formatted, err)
+			continue
+		}
+		if got := reparsed.To[0].TagValue; got != value {
+			t.Errorf("expected tag value %q, got %q", value, got)
+		}
+	}
+}
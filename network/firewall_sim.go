@@ -0,0 +1,329 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/compute"
+)
+
+const (
+	TrafficInbound  = "inbound"
+	TrafficOutbound = "outbound"
+)
+
+// FirewallSimulator evaluates traffic against a set of firewall rules and
+// instances offline, answering whether the traffic would be allowed and which
+// rule decided it. Rules and instances are typically fetched with
+// FirewallClient.ListRules and compute's InstancesClient.List.
+//
+// The simulator follows the Triton firewall semantics: on an instance with its
+// firewall enabled, inbound traffic is blocked and outbound traffic allowed
+// unless a rule says otherwise. A rule only applies to an instance selected by
+// a vm, tag or all vms target. When several rules match, the one with the
+// highest priority wins, and BLOCK wins over ALLOW at equal priority.
+type FirewallSimulator struct {
+	rules     []*simulatedRule
+	instances map[string]*compute.Instance
+	byIP      map[string]*compute.Instance
+}
+
+type simulatedRule struct {
+	rule   *FirewallRule
+	parsed *Rule
+}
+
+// NewFirewallSimulator returns a simulator for the given rules and instances.
+// Disabled rules are ignored. An error is returned if an enabled rule can not
+// be parsed.
+func NewFirewallSimulator(rules []*FirewallRule, instances []*compute.Instance) (*FirewallSimulator, error) {
+	sim := &FirewallSimulator{
+		instances: make(map[string]*compute.Instance, len(instances)),
+		byIP:      make(map[string]*compute.Instance),
+	}
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		parsed, err := rule.Parse()
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("Error parsing firewall rule %s: {{err}}", rule.ID), err)
+		}
+		sim.rules = append(sim.rules, &simulatedRule{rule: rule, parsed: parsed})
+	}
+
+	for _, instance := range instances {
+		sim.instances[instance.ID] = instance
+		for _, ip := range instance.IPs {
+			sim.byIP[ip] = instance
+		}
+	}
+
+	return sim, nil
+}
+
+// TrafficEndpoint is the source or destination of simulated traffic. Either
+// InstanceID or IP must be set. An IP belonging to a known instance is treated
+// as that instance.
+type TrafficEndpoint struct {
+	InstanceID string
+	IP         string
+}
+
+// SimulateTrafficInput represents parameters to a Simulate operation.
+type SimulateTrafficInput struct {
+	From TrafficEndpoint
+	To   TrafficEndpoint
+
+	// Protocol is one of the RuleProtocol constants.
+	Protocol string
+
+	// Port is the destination port of tcp and udp traffic.
+	Port int
+
+	// ICMPType and ICMPCode describe icmp and icmp6 traffic.
+	ICMPType int
+	ICMPCode int
+}
+
+// TrafficDecision is the outcome of a Simulate operation.
+type TrafficDecision struct {
+	Allowed bool
+
+	// Rule is the rule which decided the traffic, or nil if it was decided
+	// by the default policy.
+	Rule *FirewallRule
+
+	// Direction is TrafficOutbound if the decision was made on the source
+	// instance and TrafficInbound if it was made on the destination.
+	Direction string
+
+	// Reason describes the decision in human-readable form.
+	Reason string
+}
+
+type resolvedEndpoint struct {
+	instance *compute.Instance
+	ips      []net.IP
+}
+
+func (s *FirewallSimulator) resolve(endpoint TrafficEndpoint) (*resolvedEndpoint, error) {
+	resolved := &resolvedEndpoint{}
+	switch {
+	case endpoint.InstanceID != "":
+		instance, ok := s.instances[endpoint.InstanceID]
+		if !ok {
+			return nil, fmt.Errorf("unknown instance %q", endpoint.InstanceID)
+		}
+		resolved.instance = instance
+		if endpoint.IP == "" {
+			for _, ip := range instance.IPs {
+				if parsed := net.ParseIP(ip); parsed != nil {
+					resolved.ips = append(resolved.ips, parsed)
+				}
+			}
+			return resolved, nil
+		}
+	case endpoint.IP != "":
+		resolved.instance = s.byIP[endpoint.IP]
+	default:
+		return nil, errors.New("endpoint requires an InstanceID or IP")
+	}
+
+	ip := net.ParseIP(endpoint.IP)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", endpoint.IP)
+	}
+	resolved.ips = []net.IP{ip}
+	return resolved, nil
+}
+
+// Simulate reports whether the described traffic would be allowed.
+func (s *FirewallSimulator) Simulate(input *SimulateTrafficInput) (*TrafficDecision, error) {
+	from, err := s.resolve(input.From)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error resolving traffic source: {{err}}", err)
+	}
+	to, err := s.resolve(input.To)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error resolving traffic destination: {{err}}", err)
+	}
+
+	var outbound *simulatedRule
+	if from.instance != nil && from.instance.FirewallEnabled {
+		outbound = s.decide(input, from, to, true)
+		if outbound != nil && outbound.parsed.Action == RuleActionBlock {
+			return &TrafficDecision{
+				Allowed:   false,
+				Rule:      outbound.rule,
+				Direction: TrafficOutbound,
+				Reason:    fmt.Sprintf("blocked leaving %s by rule %s", from.instance.ID, outbound.rule.ID),
+			}, nil
+		}
+	}
+
+	if to.instance == nil || !to.instance.FirewallEnabled {
+		decision := &TrafficDecision{
+			Allowed:   true,
+			Direction: TrafficInbound,
+			Reason:    "destination has no firewall enabled",
+		}
+		if outbound != nil {
+			decision.Rule = outbound.rule
+			decision.Direction = TrafficOutbound
+			decision.Reason = fmt.Sprintf("allowed leaving %s by rule %s", from.instance.ID, outbound.rule.ID)
+		}
+		return decision, nil
+	}
+
+	inbound := s.decide(input, from, to, false)
+	if inbound == nil {
+		return &TrafficDecision{
+			Allowed:   false,
+			Direction: TrafficInbound,
+			Reason:    fmt.Sprintf("blocked entering %s by default inbound policy", to.instance.ID),
+		}, nil
+	}
+
+	allowed := inbound.parsed.Action == RuleActionAllow
+	verb := "blocked"
+	if allowed {
+		verb = "allowed"
+	}
+	return &TrafficDecision{
+		Allowed:   allowed,
+		Rule:      inbound.rule,
+		Direction: TrafficInbound,
+		Reason:    fmt.Sprintf("%s entering %s by rule %s", verb, to.instance.ID, inbound.rule.ID),
+	}, nil
+}
+
+// decide returns the rule deciding the traffic on the source instance when
+// outbound is true, or on the destination instance otherwise. It returns nil if
+// no rule matches and the default policy applies.
+func (s *FirewallSimulator) decide(input *SimulateTrafficInput, from, to *resolvedEndpoint, outbound bool) *simulatedRule {
+	var decided *simulatedRule
+	for _, candidate := range s.rules {
+		rule := candidate.parsed
+		if !ruleMatchesTraffic(rule, input) {
+			continue
+		}
+
+		// The side of the rule on the instance enforcing it must select
+		// that instance itself, while the other side may match by
+		// address too.
+		local, remote := to, from
+		localTargets, remoteTargets := rule.To, rule.From
+		if outbound {
+			local, remote = from, to
+			localTargets, remoteTargets = rule.From, rule.To
+		}
+		if !targetsSelectInstance(localTargets, local.instance) {
+			continue
+		}
+		if !targetsMatchEndpoint(remoteTargets, remote) {
+			continue
+		}
+
+		if decided == nil || rule.Priority > decided.parsed.Priority ||
+			(rule.Priority == decided.parsed.Priority && rule.Action == RuleActionBlock && decided.parsed.Action != RuleActionBlock) {
+			decided = candidate
+		}
+	}
+	return decided
+}
+
+func ruleMatchesTraffic(rule *Rule, input *SimulateTrafficInput) bool {
+	if rule.Protocol != input.Protocol {
+		return false
+	}
+
+	switch rule.Protocol {
+	case RuleProtocolTCP, RuleProtocolUDP:
+		if rule.AllPorts {
+			return true
+		}
+		for _, port := range rule.Ports {
+			if input.Port >= port.Start && input.Port <= port.End {
+				return true
+			}
+		}
+		return false
+	case RuleProtocolICMP, RuleProtocolICMP6:
+		if rule.AllPorts {
+			return true
+		}
+		for _, icmpType := range rule.ICMPTypes {
+			if icmpType.Type == input.ICMPType && (!icmpType.HasCode || icmpType.Code == input.ICMPCode) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func targetsSelectInstance(targets []RuleTarget, instance *compute.Instance) bool {
+	for _, target := range targets {
+		if targetSelectsInstance(target, instance) {
+			return true
+		}
+	}
+	return false
+}
+
+func targetSelectsInstance(target RuleTarget, instance *compute.Instance) bool {
+	if instance == nil {
+		return false
+	}
+
+	switch target.Type {
+	case RuleTargetAllVMs:
+		return true
+	case RuleTargetVM:
+		return target.Value == instance.ID
+	case RuleTargetTag:
+		value, ok := instance.Tags[target.Value]
+		if !ok {
+			return false
+		}
+		return target.TagValue == "" || fmt.Sprint(value) == target.TagValue
+	default:
+		return false
+	}
+}
+
+func targetsMatchEndpoint(targets []RuleTarget, endpoint *resolvedEndpoint) bool {
+	for _, target := range targets {
+		switch target.Type {
+		case RuleTargetAny:
+			return true
+		case RuleTargetIP:
+			targetIP := net.ParseIP(target.Value)
+			for _, ip := range endpoint.ips {
+				if targetIP.Equal(ip) {
+					return true
+				}
+			}
+		case RuleTargetSubnet:
+			_, subnet, err := net.ParseCIDR(target.Value)
+			if err != nil {
+				continue
+			}
+			for _, ip := range endpoint.ips {
+				if subnet.Contains(ip) {
+					return true
+				}
+			}
+		default:
+			if targetSelectsInstance(target, endpoint.instance) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/joyent/triton-go/compute"
+)
+
+const (
+	simWebID = "11111111-1111-1111-1111-111111111111"
+	simDBID  = "22222222-2222-2222-2222-222222222222"
+	simOffID = "33333333-3333-3333-3333-333333333333"
+)
+
+func newTestSimulator(t *testing.T) *FirewallSimulator {
+	rules := []*FirewallRule{
+		{ID: "web", Enabled: true, Rule: `FROM any TO tag "role" = "web" ALLOW tcp (PORT 80 AND PORT 443)`},
+		{ID: "db", Enabled: true, Rule: `FROM tag "role" = "web" TO tag "role" = "db" ALLOW tcp PORT 5432`},
+		{ID: "block-bad", Enabled: true, Rule: `FROM subnet 10.66.0.0/16 TO all vms BLOCK tcp PORT all`},
+		{ID: "allow-bad", Enabled: true, Rule: `FROM ip 10.66.0.5 TO all vms ALLOW tcp PORT 443 PRIORITY 5`},
+		{ID: "no-smtp", Enabled: true, Rule: `FROM all vms TO any BLOCK tcp PORT 25`},
+		{ID: "disabled", Enabled: false, Rule: `FROM any TO all vms ALLOW tcp PORT 22`},
+	}
+	instances := []*compute.Instance{
+		{ID: simWebID, IPs: []string{"10.0.0.10"}, FirewallEnabled: true, Tags: map[string]interface{}{"role": "web"}},
+		{ID: simDBID, IPs: []string{"10.0.0.20"}, FirewallEnabled: true, Tags: map[string]interface{}{"role": "db"}},
+		{ID: simOffID, IPs: []string{"10.0.0.30"}, FirewallEnabled: false},
+	}
+
+	sim, err := NewFirewallSimulator(rules, instances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim
+}
+
+func TestFirewallSimulator(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	cases := []struct {
+		name      string
+		input     *SimulateTrafficInput
+		allowed   bool
+		ruleID    string
+		direction string
+	}{
+		{
+			name:      "web allowed from anywhere",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "203.0.113.1"}, To: TrafficEndpoint{InstanceID: simWebID}, Protocol: "tcp", Port: 443},
+			allowed:   true,
+			ruleID:    "web",
+			direction: TrafficInbound,
+		},
+		{
+			name:      "default inbound block",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "203.0.113.1"}, To: TrafficEndpoint{InstanceID: simWebID}, Protocol: "tcp", Port: 22},
+			allowed:   false,
+			direction: TrafficInbound,
+		},
+		{
+			name:      "db from web by IP",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "10.0.0.10"}, To: TrafficEndpoint{IP: "10.0.0.20"}, Protocol: "tcp", Port: 5432},
+			allowed:   true,
+			ruleID:    "db",
+			direction: TrafficInbound,
+		},
+		{
+			name:      "db not from outside",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "203.0.113.1"}, To: TrafficEndpoint{InstanceID: simDBID}, Protocol: "tcp", Port: 5432},
+			allowed:   false,
+			direction: TrafficInbound,
+		},
+		{
+			name:      "block wins at equal priority",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "10.66.1.1"}, To: TrafficEndpoint{InstanceID: simWebID}, Protocol: "tcp", Port: 80},
+			allowed:   false,
+			ruleID:    "block-bad",
+			direction: TrafficInbound,
+		},
+		{
+			name:      "higher priority wins",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "10.66.0.5"}, To: TrafficEndpoint{InstanceID: simWebID}, Protocol: "tcp", Port: 443},
+			allowed:   true,
+			ruleID:    "allow-bad",
+			direction: TrafficInbound,
+		},
+		{
+			name:      "outbound block",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{InstanceID: simWebID}, To: TrafficEndpoint{IP: "198.51.100.1"}, Protocol: "tcp", Port: 25},
+			allowed:   false,
+			ruleID:    "no-smtp",
+			direction: TrafficOutbound,
+		},
+		{
+			name:      "firewall disabled on destination",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "203.0.113.1"}, To: TrafficEndpoint{InstanceID: simOffID}, Protocol: "tcp", Port: 22},
+			allowed:   true,
+			direction: TrafficInbound,
+		},
+		{
+			name:      "disabled rule ignored",
+			input:     &SimulateTrafficInput{From: TrafficEndpoint{IP: "203.0.113.1"}, To: TrafficEndpoint{InstanceID: simDBID}, Protocol: "tcp", Port: 22},
+			allowed:   false,
+			direction: TrafficInbound,
+		},
+	}
+
+	for _, c := range cases {
+		decision, err := sim.Simulate(c.input)
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if decision.Allowed != c.allowed {
+			t.Errorf("%s: expected allowed %t, got %t (%s)", c.name, c.allowed, decision.Allowed, decision.Reason)
+		}
+		ruleID := ""
+		if decision.Rule != nil {
+			ruleID = decision.Rule.ID
+		}
+		if ruleID != c.ruleID {
+			t.Errorf("%s: expected rule %q, got %q", c.name, c.ruleID, ruleID)
+		}
+		if decision.Direction != c.direction {
+			t.Errorf("%s: expected direction %q, got %q", c.name, c.direction, decision.Direction)
+		}
+	}
+}
+
+func TestFirewallSimulatorErrors(t *testing.T) {
+	_, err := NewFirewallSimulator([]*FirewallRule{{ID: "bad", Enabled: true, Rule: "FROM nowhere"}}, nil)
+	if err == nil {
+		t.Error("expected error for invalid rule")
+	}
+
+	sim := newTestSimulator(t)
+	_, err = sim.Simulate(&SimulateTrafficInput{
+		From:     TrafficEndpoint{InstanceID: "missing"},
+		To:       TrafficEndpoint{InstanceID: simWebID},
+		Protocol: "tcp",
+		Port:     80,
+	})
+	if err == nil {
+		t.Error("expected error for unknown instance")
+	}
+}
@@ -0,0 +1,226 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// networkIPsPageSize is the number of IPs requested per page when listing every
+// IP of a network.
+const networkIPsPageSize = 1000
+
+// NetworkIP represents an IP address within a network which is either in use
+// or reserved.
+type NetworkIP struct {
+	IP       string `json:"ip"`
+	Reserved bool   `json:"reserved"`
+
+	// Managed is true for IPs used by Triton itself, such as the gateway,
+	// which can not be reserved or unreserved.
+	Managed bool `json:"managed"`
+
+	// OwnerID is the account owning the instance the IP belongs to.
+	OwnerID string `json:"owner_uuid"`
+
+	// BelongsToID is the ID of the instance the IP belongs to, if any.
+	BelongsToID string `json:"belongs_to_uuid"`
+}
+
+type ListIPsInput struct {
+	NetworkID string
+
+	// Limit and Offset page through the IPs of the network. If Limit is
+	// zero, CloudAPI's default page size is used.
+	Limit  uint
+	Offset uint
+}
+
+func (c *NetworkClient) ListIPs(ctx context.Context, input *ListIPsInput) ([]*NetworkIP, error) {
+	path := fmt.Sprintf("/%s/networks/%s/ips", c.Client.AccountName, input.NetworkID)
+	query := &url.Values{}
+	if input.Limit > 0 {
+		query.Set("limit", fmt.Sprintf("%d", input.Limit))
+	}
+	if input.Offset > 0 {
+		query.Set("offset", fmt.Sprintf("%d", input.Offset))
+	}
+
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+		Query:  query,
+	}
+	respReader, err := c.Client.ExecuteRequestURIParams(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListIPs request: {{err}}", err)
+	}
+
+	var result []*NetworkIP
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ListIPs response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+// listAllIPs lists every in-use or reserved IP of a network, following pages
+// until a short page is returned.
+func (c *NetworkClient) listAllIPs(ctx context.Context, networkID string) ([]*NetworkIP, error) {
+	var result []*NetworkIP
+	for offset := uint(0); ; offset += networkIPsPageSize {
+		page, err := c.ListIPs(ctx, &ListIPsInput{
+			NetworkID: networkID,
+			Limit:     networkIPsPageSize,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page...)
+		if len(page) < networkIPsPageSize {
+			return result, nil
+		}
+	}
+}
+
+type GetIPInput struct {
+	NetworkID string
+	IP        string
+}
+
+func (c *NetworkClient) GetIP(ctx context.Context, input *GetIPInput) (*NetworkIP, error) {
+	path := fmt.Sprintf("/%s/networks/%s/ips/%s", c.Client.AccountName, input.NetworkID, input.IP)
+	reqInputs := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respReader, err := c.Client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetIP request: {{err}}", err)
+	}
+
+	var result *NetworkIP
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding GetIP response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type UpdateIPInput struct {
+	NetworkID string `json:"-"`
+	IP        string `json:"-"`
+	Reserved  bool   `json:"reserved"`
+}
+
+// UpdateIP reserves or unreserves an IP address of a network. A reserved IP is
+// never handed out to an instance automatically.
+func (c *NetworkClient) UpdateIP(ctx context.Context, input *UpdateIPInput) (*NetworkIP, error) {
+	path := fmt.Sprintf("/%s/networks/%s/ips/%s", c.Client.AccountName, input.NetworkID, input.IP)
+	reqInputs := client.RequestInput{
+		Method: http.MethodPut,
+		Path:   path,
+		Body:   input,
+	}
+	respReader, err := c.Client.ExecuteRequest(ctx, reqInputs)
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing UpdateIP request: {{err}}", err)
+	}
+
+	var result *NetworkIP
+	decoder := json.NewDecoder(respReader)
+	if err = decoder.Decode(&result); err != nil {
+		return nil, errwrap.Wrapf("Error decoding UpdateIP response: {{err}}", err)
+	}
+
+	return result, nil
+}
+
+type FindFreeIPInput struct {
+	NetworkID string
+
+	// Reserve reserves the IP found, so that it is not handed out to an
+	// instance before it is used.
+	Reserve bool
+}
+
+// FindFreeIP returns the first IP in the provisioning range of a network which
+// is neither in use nor reserved. Only IPv4 networks are supported.
+func (c *NetworkClient) FindFreeIP(ctx context.Context, input *FindFreeIPInput) (*NetworkIP, error) {
+	network, err := c.Get(ctx, &GetInput{ID: input.NetworkID})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing FindFreeIP request: {{err}}", err)
+	}
+
+	used, err := c.listAllIPs(ctx, input.NetworkID)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing FindFreeIP request: {{err}}", err)
+	}
+
+	ip, err := firstFreeIP(network, used)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing FindFreeIP request: {{err}}", err)
+	}
+
+	if !input.Reserve {
+		return &NetworkIP{IP: ip.String()}, nil
+	}
+
+	reserved, err := c.UpdateIP(ctx, &UpdateIPInput{
+		NetworkID: input.NetworkID,
+		IP:        ip.String(),
+		Reserved:  true,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing FindFreeIP request: {{err}}", err)
+	}
+
+	return reserved, nil
+}
+
+// firstFreeIP walks the provisioning range of a network and returns the first
+// address which is not the gateway and not in used.
+func firstFreeIP(network *Network, used []*NetworkIP) (net.IP, error) {
+	start := net.ParseIP(network.ProvisioningStartIP).To4()
+	end := net.ParseIP(network.ProvisioningEndIP).To4()
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("network %s has no IPv4 provisioning range", network.Id)
+	}
+
+	taken := make(map[uint32]bool, len(used)+1)
+	for _, networkIP := range used {
+		if ip := net.ParseIP(networkIP.IP).To4(); ip != nil {
+			taken[ipv4ToUint32(ip)] = true
+		}
+	}
+	if gateway := net.ParseIP(network.Gateway).To4(); gateway != nil {
+		taken[ipv4ToUint32(gateway)] = true
+	}
+
+	last := ipv4ToUint32(end)
+	for candidate := uint64(ipv4ToUint32(start)); candidate <= uint64(last); candidate++ {
+		if !taken[uint32(candidate)] {
+			return uint32ToIPv4(uint32(candidate)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no free IP in network %s", network.Id)
+}
@@ -0,0 +1,91 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/joyent/triton-go/testutils/testclient"
+)
+
+func TestFirstFreeIP(t *testing.T) {
+	network := &Network{
+		Id:                  "net",
+		Gateway:             "10.0.0.3",
+		ProvisioningStartIP: "10.0.0.2",
+		ProvisioningEndIP:   "10.0.0.6",
+	}
+	used := []*NetworkIP{
+		{IP: "10.0.0.2", BelongsToID: "instance"},
+		{IP: "10.0.0.4", Reserved: true},
+	}
+
+	ip, err := firstFreeIP(network, used)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.String() != "10.0.0.5" {
+		t.Errorf("expected 10.0.0.5, got %s", ip)
+	}
+
+	used = append(used, &NetworkIP{IP: "10.0.0.5"}, &NetworkIP{IP: "10.0.0.6"})
+	if _, err := firstFreeIP(network, used); err == nil {
+		t.Error("expected error for full network")
+	}
+
+	if _, err := firstFreeIP(&Network{Id: "v6", ProvisioningStartIP: "fd00::2", ProvisioningEndIP: "fd00::ff"}, nil); err == nil {
+		t.Error("expected error for IPv6 network")
+	}
+}
+
+// testNetworkClient returns a NetworkClient for the account "acct" whose
+// requests are served by handler.
+func testNetworkClient(t *testing.T, handler http.HandlerFunc) *NetworkClient {
+	return &NetworkClient{Client: testclient.New(t, handler)}
+}
+
+func TestListAllIPs(t *testing.T) {
+	// More IPs than a uint16 offset can page through.
+	const total = 66500
+
+	var offsets []int
+	c := testNetworkClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/acct/networks/net/ips" {
+			http.NotFound(w, r)
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, offset)
+
+		page := []*NetworkIP{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			page = append(page, &NetworkIP{IP: fmt.Sprintf("10.%d.%d.%d", i>>16, (i>>8)&0xff, i&0xff)})
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	ips, err := c.listAllIPs(context.Background(), "net")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ips) != total {
+		t.Fatalf("expected %d IPs, got %d", total, len(ips))
+	}
+	if last := ips[total-1].IP; last != "10.1.3.195" {
+		t.Fatalf("expected the last IP to be 10.1.3.195, got %s", last)
+	}
+
+	pages := (total + networkIPsPageSize - 1) / networkIPsPageSize
+	if len(offsets) != pages {
+		t.Fatalf("expected %d pages, got %d", pages, len(offsets))
+	}
+	for i, offset := range offsets {
+		if offset != i*networkIPsPageSize {
+			t.Fatalf("expected page %d at offset %d, got %d", i, i*networkIPsPageSize, offset)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// SubnetPlan is a subnet allocated by PlanSubnet, together with the gateway
+// and provisioning range derived from it.
+type SubnetPlan struct {
+	Subnet           string
+	Gateway          string
+	ProvisionStartIP string
+	ProvisionEndIP   string
+}
+
+// CreateFabricInput returns the input to create a fabric network named name
+// using the planned subnet, on a VLAN of the named fabric. An empty
+// fabricName selects the "default" fabric.
+func (p *SubnetPlan) CreateFabricInput(fabricName string, fabricVLANID int, name string) *CreateFabricInput {
+	return &CreateFabricInput{
+		FabricName:       fabricName,
+		FabricVLANID:     fabricVLANID,
+		Name:             name,
+		Subnet:           p.Subnet,
+		Gateway:          p.Gateway,
+		ProvisionStartIP: p.ProvisionStartIP,
+		ProvisionEndIP:   p.ProvisionEndIP,
+	}
+}
+
+func ipv4ToUint32(ip net.IP) uint32 {
+	return binary.BigEndian.Uint32(ip.To4())
+}
+
+func uint32ToIPv4(n uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip
+}
+
+// parseIPv4Net parses an IPv4 CIDR, returning an error for IPv6 subnets.
+func parseIPv4Net(cidr string) (*net.IPNet, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	if subnet.IP.To4() == nil {
+		return nil, fmt.Errorf("subnet %s is not IPv4", cidr)
+	}
+	return subnet, nil
+}
+
+func subnetsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// PlanSubnet allocates the first subnet of the given prefix length within
+// parent which does not overlap any of the existing networks. The gateway is
+// the first host address, and the provisioning range covers the remaining
+// hosts. Only IPv4 is supported; existing networks without an IPv4 subnet are
+// ignored.
+func PlanSubnet(parent string, prefixLen int, existing []*Network) (*SubnetPlan, error) {
+	parentNet, err := parseIPv4Net(parent)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error parsing parent range: {{err}}", err)
+	}
+	parentLen, _ := parentNet.Mask.Size()
+	if prefixLen < parentLen || prefixLen > 30 {
+		return nil, fmt.Errorf("prefix length must be between %d and 30", parentLen)
+	}
+
+	var used []*net.IPNet
+	for _, network := range existing {
+		if network.Subnet == "" {
+			continue
+		}
+		subnet, err := parseIPv4Net(network.Subnet)
+		if err != nil {
+			continue
+		}
+		used = append(used, subnet)
+	}
+
+	mask := net.CIDRMask(prefixLen, 32)
+	size := uint64(1) << uint(32-prefixLen)
+	start := uint64(ipv4ToUint32(parentNet.IP))
+	end := start + (uint64(1) << uint(32-parentLen))
+
+	for candidate := start; candidate < end; candidate += size {
+		subnet := &net.IPNet{IP: uint32ToIPv4(uint32(candidate)), Mask: mask}
+
+		overlaps := false
+		for _, other := range used {
+			if subnetsOverlap(subnet, other) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		broadcast := uint32(candidate + size - 1)
+		return &SubnetPlan{
+			Subnet:           subnet.String(),
+			Gateway:          uint32ToIPv4(uint32(candidate) + 1).String(),
+			ProvisionStartIP: uint32ToIPv4(uint32(candidate) + 2).String(),
+			ProvisionEndIP:   uint32ToIPv4(broadcast - 1).String(),
+		}, nil
+	}
+
+	return nil, errors.New("no free subnet of the requested size in parent range")
+}
+
+type PlanFabricSubnetInput struct {
+	FabricName   string
+	FabricVLANID int
+
+	// ParentRange is the CIDR range to allocate from, e.g. "10.0.0.0/16".
+	ParentRange string
+
+	// PrefixLength is the size of the subnet to allocate, e.g. 24.
+	PrefixLength int
+}
+
+// PlanFabricSubnet allocates a subnet for a new fabric network on a VLAN,
+// avoiding the networks already on that VLAN as well as every network
+// available to the account.
+func (c *NetworkClient) PlanFabricSubnet(ctx context.Context, input *PlanFabricSubnetInput) (*SubnetPlan, error) {
+	fabrics, err := c.Fabrics().List(ctx, &ListFabricsInput{
+		FabricName:   input.FabricName,
+		FabricVLANID: input.FabricVLANID,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PlanFabricSubnet request: {{err}}", err)
+	}
+
+	networks, err := c.List(ctx, &ListInput{})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PlanFabricSubnet request: {{err}}", err)
+	}
+
+	plan, err := PlanSubnet(input.ParentRange, input.PrefixLength, append(fabrics, networks...))
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PlanFabricSubnet request: {{err}}", err)
+	}
+
+	return plan, nil
+}
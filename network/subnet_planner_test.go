@@ -0,0 +1,85 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joyent/triton-go/client"
+)
+
+func TestPlanSubnet(t *testing.T) {
+	existing := []*Network{
+		{Subnet: "10.0.0.0/24"},
+		{Subnet: "10.0.1.0/25"},
+		{Subnet: "10.0.2.0/23"},
+		{Subnet: "192.168.0.0/16"},
+		{Subnet: "fd00::/64"},
+		{},
+	}
+
+	plan, err := PlanSubnet("10.0.0.0/16", 24, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &SubnetPlan{
+		Subnet:           "10.0.4.0/24",
+		Gateway:          "10.0.4.1",
+		ProvisionStartIP: "10.0.4.2",
+		ProvisionEndIP:   "10.0.4.254",
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Errorf("expected %+v, got %+v", expected, plan)
+	}
+
+	plan, err = PlanSubnet("10.0.0.0/16", 25, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Subnet != "10.0.1.128/25" {
+		t.Errorf("expected 10.0.1.128/25, got %s", plan.Subnet)
+	}
+}
+
+func TestPlanSubnetErrors(t *testing.T) {
+	if _, err := PlanSubnet("10.0.0.0/24", 24, []*Network{{Subnet: "10.0.0.0/16"}}); err == nil {
+		t.Error("expected error when parent range is full")
+	}
+	if _, err := PlanSubnet("10.0.0.0/24", 16, nil); err == nil {
+		t.Error("expected error for prefix larger than parent")
+	}
+	if _, err := PlanSubnet("10.0.0.0/24", 31, nil); err == nil {
+		t.Error("expected error for prefix too small to hold hosts")
+	}
+	if _, err := PlanSubnet("fd00::/48", 64, nil); err == nil {
+		t.Error("expected error for IPv6 parent")
+	}
+}
+
+func TestSubnetPlan_CreateFabricInput(t *testing.T) {
+	plan := &SubnetPlan{
+		Subnet:           "10.0.4.0/24",
+		Gateway:          "10.0.4.1",
+		ProvisionStartIP: "10.0.4.2",
+		ProvisionEndIP:   "10.0.4.254",
+	}
+
+	input := plan.CreateFabricInput("prod", 2, "web")
+	expected := &CreateFabricInput{
+		FabricName:       "prod",
+		FabricVLANID:     2,
+		Name:             "web",
+		Subnet:           "10.0.4.0/24",
+		Gateway:          "10.0.4.1",
+		ProvisionStartIP: "10.0.4.2",
+		ProvisionEndIP:   "10.0.4.254",
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, input)
+	}
+
+	fabrics := &FabricsClient{client: &client.Client{AccountName: "acct"}}
+	if path := fabrics.fabricPath(input.FabricName); path != "/acct/fabrics/prod" {
+		t.Fatalf("expected network to be created on the prod fabric, got path %s", path)
+	}
+}
@@ -19,10 +19,11 @@ func newStorageClient(client *client.Client) *StorageClient {
 // resources within CloudAPI
 func NewClient(config *triton.ClientConfig) (*StorageClient, error) {
 	// TODO: Utilize config interface within the function itself
-	client, err := client.New(config.TritonURL, config.MantaURL, config.AccountName, config.Signers...)
+	client, err := client.NewForUser(config.TritonURL, config.MantaURL, config.AccountName, config.Username, config.Signers...)
 	if err != nil {
 		return nil, err
 	}
+	client.AsRole = config.AsRole
 	return newStorageClient(client), nil
 }
 
@@ -49,3 +50,21 @@ func (c *StorageClient) Objects() *ObjectsClient {
 func (c *StorageClient) SnapLinks() *SnapLinksClient {
 	return &SnapLinksClient{c.Client}
 }
+
+// Versions returns a VersionsClient used for storing versioned objects on top
+// of the SnapLinks functionality of the Triton Object Storage API.
+func (c *StorageClient) Versions() *VersionsClient {
+	return &VersionsClient{c.Client}
+}
+
+// Usage returns a UsageClient used for reporting on the storage consumed by an
+// account in the Triton Object Storage API.
+func (c *StorageClient) Usage() *UsageClient {
+	return &UsageClient{c.Client}
+}
+
+// RoleTags returns a RoleTagsClient used for accessing functions pertaining
+// to the role tags of objects and directories in the Triton Object Storage API.
+func (c *StorageClient) RoleTags() *RoleTagsClient {
+	return &RoleTagsClient{c.Client}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/errwrap"
@@ -25,6 +26,7 @@ type DirectoryEntry struct {
 	Name         string    `json:"name"`
 	Size         uint64    `json:"size"`
 	Type         string    `json:"type"`
+	Durability   uint64    `json:"durability"`
 }
 
 // ListDirectoryInput represents parameters to a ListDirectory operation.
@@ -42,13 +44,19 @@ type ListDirectoryOutput struct {
 
 // List lists the contents of a directory on the Triton Object Store service.
 func (s *DirectoryClient) List(ctx context.Context, input *ListDirectoryInput) (*ListDirectoryOutput, error) {
+	return s.list(ctx, input, "manta_path")
+}
+
+// list lists the contents of a directory, sending Marker as the query
+// parameter markerParam.
+func (s *DirectoryClient) list(ctx context.Context, input *ListDirectoryInput, markerParam string) (*ListDirectoryOutput, error) {
 	path := fmt.Sprintf("/%s%s", s.client.AccountName, input.DirectoryName)
 	query := &url.Values{}
 	if input.Limit != 0 {
 		query.Set("limit", strconv.FormatUint(input.Limit, 10))
 	}
 	if input.Marker != "" {
-		query.Set("manta_path", input.Marker)
+		query.Set(markerParam, input.Marker)
 	}
 
 	reqInput := client.RequestInput{
@@ -65,9 +73,9 @@ func (s *DirectoryClient) List(ctx context.Context, input *ListDirectoryInput) (
 	}
 
 	var results []*DirectoryEntry
+	decoder := json.NewDecoder(respBody)
 	for {
 		current := &DirectoryEntry{}
-		decoder := json.NewDecoder(respBody)
 		if err = decoder.Decode(&current); err != nil {
 			if err == io.EOF {
 				break
@@ -89,9 +97,85 @@ func (s *DirectoryClient) List(ctx context.Context, input *ListDirectoryInput) (
 	return output, nil
 }
 
+// directoryPageSize is the number of entries requested per page when listing
+// a whole directory. It is the maximum value accepted by Manta.
+const directoryPageSize = 1024
+
+// listAll lists every entry of a directory, following the Manta "marker"
+// parameter until the listing is exhausted.
+func (s *DirectoryClient) listAll(ctx context.Context, directoryName string) ([]*DirectoryEntry, error) {
+	var entries []*DirectoryEntry
+	marker := ""
+	for {
+		output, err := s.list(ctx, &ListDirectoryInput{
+			DirectoryName: directoryName,
+			Limit:         directoryPageSize,
+			Marker:        marker,
+		}, "marker")
+		if err != nil {
+			return nil, err
+		}
+
+		page := output.Entries
+		// Manta includes the marker entry itself at the start of each
+		// subsequent page.
+		if marker != "" && len(page) > 0 && page[0].Name == marker {
+			page = page[1:]
+		}
+		entries = append(entries, page...)
+
+		if len(output.Entries) < directoryPageSize || len(page) == 0 {
+			break
+		}
+		marker = page[len(page)-1].Name
+	}
+
+	return entries, nil
+}
+
+// WalkDirectoryInput represents parameters to a WalkDirectory operation.
+type WalkDirectoryInput struct {
+	DirectoryName string
+}
+
+// WalkDirectoryFunc is called by Walk for every entry beneath the directory
+// being walked. The path of the entry is relative to the account root, in the
+// same form accepted by the other DirectoryClient and ObjectsClient functions.
+// Returning an error stops the walk and the error is returned by Walk.
+type WalkDirectoryFunc func(path string, entry *DirectoryEntry) error
+
+// Walk recursively visits every object and directory beneath DirectoryName,
+// depth-first and in lexical order, calling fn for each entry.
+func (s *DirectoryClient) Walk(ctx context.Context, input *WalkDirectoryInput, fn WalkDirectoryFunc) error {
+	entries, err := s.listAll(ctx, input.DirectoryName)
+	if err != nil {
+		return errwrap.Wrapf("Error executing WalkDirectory request: {{err}}", err)
+	}
+
+	for _, entry := range entries {
+		entryPath := fmt.Sprintf("%s/%s", strings.TrimSuffix(input.DirectoryName, "/"), entry.Name)
+		if err := fn(entryPath, entry); err != nil {
+			return err
+		}
+
+		if entry.Type == "directory" {
+			err := s.Walk(ctx, &WalkDirectoryInput{DirectoryName: entryPath}, fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // PutDirectoryInput represents parameters to a PutDirectory operation.
 type PutDirectoryInput struct {
 	DirectoryName string
+
+	// RoleTags are the names of the roles given access to the directory.
+	// Optional.
+	RoleTags []string
 }
 
 // Put puts a directoy into the Triton Object Storage service is an idempotent
@@ -101,6 +185,7 @@ func (s *DirectoryClient) Put(ctx context.Context, input *PutDirectoryInput) err
 	path := fmt.Sprintf("/%s%s", s.client.AccountName, input.DirectoryName)
 	headers := &http.Header{}
 	headers.Set("Content-Type", "application/json; type=directory")
+	setRoleTagHeader(headers, input.RoleTags)
 
 	reqInput := client.RequestInput{
 		Method:  http.MethodPut,
@@ -326,8 +326,9 @@ type GetJobOutputOutput struct {
 }
 
 // GetJobOutput returns the current "live" set of outputs from a job. Think of
-// this like `tail -f`. If error is nil (i.e. the operation is successful), it is
-// your responsibility to close the io.ReadCloser named Items in the output.
+// this like `tail -f`. If error is nil (i.e. the operation is successful), Items
+// is the open response body: it is not closed when GetJobOutput returns, and it
+// is your responsibility to close it once you are done reading.
 func (s *JobClient) GetOutput(ctx context.Context, input *GetJobOutputInput) (*GetJobOutputOutput, error) {
 	path := fmt.Sprintf("/%s/jobs/%s/live/out", s.client.AccountName, input.JobID)
 
@@ -336,9 +337,6 @@ func (s *JobClient) GetOutput(ctx context.Context, input *GetJobOutputInput) (*G
 		Path:   path,
 	}
 	respBody, respHeader, err := s.client.ExecuteRequestStorage(ctx, reqInput)
-	if respBody != nil {
-		defer respBody.Close()
-	}
 	if err != nil {
 		return nil, errwrap.Wrapf("Error executing GetJobOutput request: {{err}}", err)
 	}
@@ -368,8 +366,9 @@ type GetJobInputOutput struct {
 }
 
 // GetJobInput returns the current "live" set of inputs from a job. Think of
-// this like `tail -f`. If error is nil (i.e. the operation is successful), it is
-// your responsibility to close the io.ReadCloser named Items in the output.
+// this like `tail -f`. If error is nil (i.e. the operation is successful), Items
+// is the open response body: it is not closed when GetJobInput returns, and it
+// is your responsibility to close it once you are done reading.
 func (s *JobClient) GetInput(ctx context.Context, input *GetJobInputInput) (*GetJobInputOutput, error) {
 	path := fmt.Sprintf("/%s/jobs/%s/live/in", s.client.AccountName, input.JobID)
 
@@ -378,9 +377,6 @@ func (s *JobClient) GetInput(ctx context.Context, input *GetJobInputInput) (*Get
 		Path:   path,
 	}
 	respBody, respHeader, err := s.client.ExecuteRequestStorage(ctx, reqInput)
-	if respBody != nil {
-		defer respBody.Close()
-	}
 	if err != nil {
 		return nil, errwrap.Wrapf("Error executing GetJobInput request: {{err}}", err)
 	}
@@ -410,8 +406,9 @@ type GetJobFailuresOutput struct {
 }
 
 // GetJobFailures returns the current "live" set of outputs from a job. Think of
-// this like `tail -f`. If error is nil (i.e. the operation is successful), it is
-// your responsibility to close the io.ReadCloser named Items in the output.
+// this like `tail -f`. If error is nil (i.e. the operation is successful), Items
+// is the open response body: it is not closed when GetJobFailures returns, and it
+// is your responsibility to close it once you are done reading.
 func (s *JobClient) GetFailures(ctx context.Context, input *GetJobFailuresInput) (*GetJobFailuresOutput, error) {
 	path := fmt.Sprintf("/%s/jobs/%s/live/fail", s.client.AccountName, input.JobID)
 
@@ -420,9 +417,6 @@ func (s *JobClient) GetFailures(ctx context.Context, input *GetJobFailuresInput)
 		Path:   path,
 	}
 	respBody, respHeader, err := s.client.ExecuteRequestStorage(ctx, reqInput)
-	if respBody != nil {
-		defer respBody.Close()
-	}
 	if err != nil {
 		return nil, errwrap.Wrapf("Error executing GetJobFailures request: {{err}}", err)
 	}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+const (
+	defaultJobInputBatchSize = 500
+	defaultJobPollInterval   = 5 * time.Second
+)
+
+// JobInputSource supplies the object paths fed into a job by RunJob. Paths are
+// full Manta paths, including the leading account name.
+type JobInputSource interface {
+	// Next returns the next object path, or io.EOF once the source is
+	// exhausted.
+	Next(ctx context.Context) (string, error)
+}
+
+type sliceJobInputs struct {
+	paths []string
+}
+
+// JobInputsFromSlice returns a JobInputSource producing the given object paths.
+func JobInputsFromSlice(paths []string) JobInputSource {
+	return &sliceJobInputs{paths: paths}
+}
+
+func (s *sliceJobInputs) Next(_ context.Context) (string, error) {
+	if len(s.paths) == 0 {
+		return "", io.EOF
+	}
+	path := s.paths[0]
+	s.paths = s.paths[1:]
+	return path, nil
+}
+
+type channelJobInputs struct {
+	ch <-chan string
+}
+
+// JobInputsFromChannel returns a JobInputSource producing the object paths
+// received on ch. The source is exhausted once ch is closed.
+func JobInputsFromChannel(ch <-chan string) JobInputSource {
+	return &channelJobInputs{ch: ch}
+}
+
+func (c *channelJobInputs) Next(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case path, ok := <-c.ch:
+		if !ok {
+			return "", io.EOF
+		}
+		return path, nil
+	}
+}
+
+type directoryJobInputs struct {
+	dir     *DirectoryClient
+	pending []string
+	objects []string
+}
+
+// JobInputsFromDirectory returns a JobInputSource producing every object
+// beneath directoryName. Directories are listed lazily as inputs are consumed,
+// so large trees are never held in memory at once.
+func JobInputsFromDirectory(dir *DirectoryClient, directoryName string) JobInputSource {
+	return &directoryJobInputs{
+		dir:     dir,
+		pending: []string{strings.TrimSuffix(directoryName, "/")},
+	}
+}
+
+func (d *directoryJobInputs) Next(ctx context.Context) (string, error) {
+	for len(d.objects) == 0 {
+		if len(d.pending) == 0 {
+			return "", io.EOF
+		}
+
+		current := d.pending[0]
+		d.pending = d.pending[1:]
+
+		entries, err := d.dir.listAll(ctx, current)
+		if err != nil {
+			return "", err
+		}
+
+		var subdirs []string
+		for _, entry := range entries {
+			entryPath := fmt.Sprintf("%s/%s", current, entry.Name)
+			if entry.Type == "directory" {
+				subdirs = append(subdirs, entryPath)
+				continue
+			}
+			d.objects = append(d.objects, fmt.Sprintf("/%s%s", d.dir.client.AccountName, entryPath))
+		}
+		d.pending = append(subdirs, d.pending...)
+	}
+
+	path := d.objects[0]
+	d.objects = d.objects[1:]
+	return path, nil
+}
+
+// RunJobInput represents parameters to a RunJob operation.
+type RunJobInput struct {
+	Name   string
+	Phases []*JobPhase
+
+	// Inputs supplies the object paths to process.
+	Inputs JobInputSource
+
+	// BatchSize is the maximum number of inputs submitted per AddInputs
+	// request. Defaults to 500.
+	BatchSize int
+
+	// PollInterval is the delay between job status checks while waiting for
+	// the job to finish. Defaults to 5 seconds.
+	PollInterval time.Duration
+
+	// Progress, if set, is called with the job statistics every time the
+	// job status is polled.
+	Progress func(stats *JobStats)
+}
+
+// RunJobOutput contains the outputs of a RunJob operation. It is your
+// responsibility to close the Outputs and Failures iterators.
+type RunJobOutput struct {
+	Job      *Job
+	Outputs  *JobLineIterator
+	Failures *JobLineIterator
+}
+
+// RunJob creates a job, streams all of its inputs in batches, ends its input
+// and waits for it to reach JobStateDone. If ctx is cancelled or an input
+// cannot be submitted, the job is cancelled before the error is returned.
+func (s *JobClient) Run(ctx context.Context, input *RunJobInput) (*RunJobOutput, error) {
+	batchSize := input.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultJobInputBatchSize
+	}
+	pollInterval := input.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultJobPollInterval
+	}
+
+	created, err := s.Create(ctx, &CreateJobInput{
+		Name:   input.Name,
+		Phases: input.Phases,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing RunJob request: {{err}}", err)
+	}
+	jobID := created.JobID
+
+	if err := s.submitInputs(ctx, jobID, input.Inputs, batchSize); err != nil {
+		s.cancelAfterError(jobID)
+		return nil, errwrap.Wrapf("Error executing RunJob request: {{err}}", err)
+	}
+
+	job, err := s.waitForDone(ctx, jobID, pollInterval, input.Progress)
+	if err != nil {
+		s.cancelAfterError(jobID)
+		return nil, errwrap.Wrapf("Error executing RunJob request: {{err}}", err)
+	}
+
+	return &RunJobOutput{
+		Job:      job,
+		Outputs:  s.StreamOutputs(ctx, &JobStreamInput{JobID: jobID}),
+		Failures: s.StreamFailures(ctx, &JobStreamInput{JobID: jobID}),
+	}, nil
+}
+
+func (s *JobClient) submitInputs(ctx context.Context, jobID string, inputs JobInputSource, batchSize int) error {
+	batch := make([]string, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.AddInputs(ctx, &AddJobInputsInput{
+			JobID:       jobID,
+			ObjectPaths: batch,
+		})
+		batch = batch[:0]
+		return err
+	}
+
+	if inputs != nil {
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			path, err := inputs.Next(ctx)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			batch = append(batch, path)
+			if len(batch) == batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return s.EndInput(ctx, &EndJobInputInput{JobID: jobID})
+}
+
+func (s *JobClient) waitForDone(ctx context.Context, jobID string, pollInterval time.Duration, progress func(*JobStats)) (*Job, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		output, err := s.Get(ctx, &GetJobInput{JobID: jobID})
+		if err != nil {
+			return nil, err
+		}
+
+		job := output.Job
+		if progress != nil && job.Stats != nil {
+			progress(job.Stats)
+		}
+		if job.State == JobStateDone {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelAfterError makes a best effort to cancel a job whose run was aborted.
+// The caller's context may already be cancelled, so a fresh one is used.
+func (s *JobClient) cancelAfterError(jobID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s.Cancel(ctx, &CancelJobInput{JobID: jobID})
+}
@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJobs serves the job endpoints of Manta for the single job "j1" of the
+// account "acct".
+type fakeJobs struct {
+	mu sync.Mutex
+
+	// runningPolls is the number of status requests reporting the job as
+	// running before it is done.
+	runningPolls int
+	// failInputs makes every AddInputs request fail.
+	failInputs bool
+	outputs    []string
+	failures   []string
+
+	created   *CreateJobInput
+	batches   [][]string
+	inputDone bool
+	cancelled bool
+	polls     int
+}
+
+func (f *fakeJobs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	route := r.Method + " " + r.URL.Path
+	switch route {
+	case "POST /acct/jobs":
+		f.created = &CreateJobInput{}
+		json.NewDecoder(r.Body).Decode(f.created)
+		w.Header().Set("Location", "/acct/jobs/j1")
+		w.WriteHeader(http.StatusCreated)
+
+	case "POST /acct/jobs/j1/live/in":
+		if f.failInputs {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":"InternalError","message":"input rejected"}`))
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		f.batches = append(f.batches, strings.Split(string(data), "\n"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case "POST /acct/jobs/j1/live/in/end":
+		f.inputDone = true
+		w.WriteHeader(http.StatusAccepted)
+
+	case "POST /acct/jobs/j1/live/cancel":
+		f.cancelled = true
+		w.WriteHeader(http.StatusAccepted)
+
+	case "GET /acct/jobs/j1/live/status":
+		f.polls++
+		job := &Job{
+			ID:        "j1",
+			State:     JobStateDone,
+			InputDone: f.inputDone,
+			Cancelled: f.cancelled,
+			Stats:     &JobStats{Tasks: uint64(len(f.batches)), TasksDone: uint64(f.polls)},
+		}
+		if f.polls <= f.runningPolls {
+			job.State = "running"
+		}
+		json.NewEncoder(w).Encode(job)
+
+	case "GET /acct/jobs/j1/live/out":
+		w.Write([]byte(strings.Join(f.outputs, "\n")))
+
+	case "GET /acct/jobs/j1/live/fail":
+		w.Write([]byte(strings.Join(f.failures, "\n")))
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"ResourceNotFound","message":"` + route + `"}`))
+	}
+}
+
+func drainJobInputs(t *testing.T, ctx context.Context, source JobInputSource) []string {
+	var paths []string
+	for {
+		path, err := source.Next(ctx)
+		if err == io.EOF {
+			return paths
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		paths = append(paths, path)
+	}
+}
+
+func drainJobLines(t *testing.T, it *JobLineIterator) []string {
+	defer it.Close()
+
+	var paths []string
+	for it.Next() {
+		paths = append(paths, it.Path())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return paths
+}
+
+func TestJobInputsFromSlice(t *testing.T) {
+	expected := []string{"/acct/stor/a", "/acct/stor/b"}
+	paths := drainJobInputs(t, context.Background(), JobInputsFromSlice(expected))
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestJobInputsFromChannel(t *testing.T) {
+	ch := make(chan string, 2)
+	ch <- "/acct/stor/a"
+	ch <- "/acct/stor/b"
+	close(ch)
+
+	paths := drainJobInputs(t, context.Background(), JobInputsFromChannel(ch))
+	expected := []string{"/acct/stor/a", "/acct/stor/b"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := JobInputsFromChannel(make(chan string)).Next(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled from an open channel, got %v", err)
+	}
+}
+
+func TestJobInputsFromDirectory(t *testing.T) {
+	c, manta := testStorageClient(t)
+	manta.putLocked("/acct/stor/data", &fakeObject{dir: true})
+	manta.putLocked("/acct/stor/data/logs", &fakeObject{dir: true})
+	manta.putLocked("/acct/stor/data/empty", &fakeObject{dir: true})
+	manta.put("/acct/stor/data/a", "a")
+	manta.put("/acct/stor/data/logs/b", "b")
+	manta.put("/acct/stor/data/z", "z")
+
+	paths := drainJobInputs(t, context.Background(), JobInputsFromDirectory(c.Dir(), "/stor/data/"))
+	expected := []string{
+		"/acct/stor/data/a",
+		"/acct/stor/data/z",
+		"/acct/stor/data/logs/b",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+
+	_, err := JobInputsFromDirectory(c.Dir(), "/stor/missing").Next(context.Background())
+	if err == nil {
+		t.Fatal("expected an error listing a missing directory")
+	}
+}
+
+func TestRunJob(t *testing.T) {
+	jobs := &fakeJobs{
+		runningPolls: 2,
+		outputs:      []string{"/acct/jobs/j1/stor/a.0", "/acct/jobs/j1/stor/b.0"},
+		failures:     []string{"/acct/stor/e"},
+	}
+	c := testStorageClientFor(t, jobs)
+
+	var progress []uint64
+	output, err := c.Jobs().Run(context.Background(), &RunJobInput{
+		Name:   "wc",
+		Phases: []*JobPhase{{Type: "map", Exec: "wc"}},
+		Inputs: JobInputsFromSlice([]string{
+			"/acct/stor/a", "/acct/stor/b", "/acct/stor/c", "/acct/stor/d", "/acct/stor/e",
+		}),
+		BatchSize:    2,
+		PollInterval: time.Millisecond,
+		Progress: func(stats *JobStats) {
+			progress = append(progress, stats.TasksDone)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if jobs.created.Name != "wc" || len(jobs.created.Phases) != 1 || jobs.created.Phases[0].Exec != "wc" {
+		t.Fatalf("unexpected job created: %+v", jobs.created)
+	}
+	expectedBatches := [][]string{
+		{"/acct/stor/a", "/acct/stor/b"},
+		{"/acct/stor/c", "/acct/stor/d"},
+		{"/acct/stor/e"},
+	}
+	if !reflect.DeepEqual(jobs.batches, expectedBatches) {
+		t.Fatalf("expected batches %v, got %v", expectedBatches, jobs.batches)
+	}
+	if !jobs.inputDone {
+		t.Fatal("expected the job input to be ended")
+	}
+	if jobs.cancelled {
+		t.Fatal("expected a successful job not to be cancelled")
+	}
+	if !reflect.DeepEqual(progress, []uint64{1, 2, 3}) {
+		t.Fatalf("expected progress on every poll, got %v", progress)
+	}
+	if output.Job.State != JobStateDone {
+		t.Fatalf("expected a done job, got %q", output.Job.State)
+	}
+
+	if paths := drainJobLines(t, output.Outputs); !reflect.DeepEqual(paths, jobs.outputs) {
+		t.Fatalf("expected outputs %v, got %v", jobs.outputs, paths)
+	}
+	if paths := drainJobLines(t, output.Failures); !reflect.DeepEqual(paths, jobs.failures) {
+		t.Fatalf("expected failures %v, got %v", jobs.failures, paths)
+	}
+}
+
+func TestRunJob_NoInputs(t *testing.T) {
+	jobs := &fakeJobs{}
+	c := testStorageClientFor(t, jobs)
+
+	_, err := c.Jobs().Run(context.Background(), &RunJobInput{
+		Name:         "empty",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(jobs.batches) != 0 || !jobs.inputDone {
+		t.Fatalf("expected the input to be ended without batches, got %v", jobs.batches)
+	}
+}
+
+func TestRunJob_CancelAfterInputError(t *testing.T) {
+	jobs := &fakeJobs{failInputs: true}
+	c := testStorageClientFor(t, jobs)
+
+	_, err := c.Jobs().Run(context.Background(), &RunJobInput{
+		Inputs:       JobInputsFromSlice([]string{"/acct/stor/a"}),
+		PollInterval: time.Millisecond,
+	})
+	if err == nil || !strings.Contains(err.Error(), "input rejected") {
+		t.Fatalf("expected the AddInputs error, got %v", err)
+	}
+	if !jobs.cancelled {
+		t.Fatal("expected the job to be cancelled")
+	}
+	if jobs.inputDone {
+		t.Fatal("expected the job input not to be ended")
+	}
+}
+
+func TestRunJob_CancelAfterContextCancelled(t *testing.T) {
+	jobs := &fakeJobs{}
+	c := testStorageClientFor(t, jobs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The channel is never closed: the run only ends once ctx is cancelled,
+	// after the first path has been received.
+	ch := make(chan string)
+	go func() {
+		ch <- "/acct/stor/a"
+		cancel()
+	}()
+
+	_, err := c.Jobs().Run(ctx, &RunJobInput{
+		Inputs:       JobInputsFromChannel(ch),
+		BatchSize:    2,
+		PollInterval: time.Millisecond,
+	})
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("expected a cancelled context error, got %v", err)
+	}
+	// The cancellation is sent with a fresh context, since ctx is done.
+	if !jobs.cancelled {
+		t.Fatal("expected the job to be cancelled")
+	}
+	if jobs.inputDone {
+		t.Fatal("expected the job input not to be ended")
+	}
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// maxJobStreamLineSize bounds the length of a single line read from a job's
+// live endpoints.
+const maxJobStreamLineSize = 1024 * 1024
+
+// JobError represents an error emitted by a task of a compute job in Manta.
+type JobError struct {
+	// Phase is the index of the phase in which the error occurred.
+	Phase int `json:"phaseNum"`
+
+	// What describes the task which failed.
+	What string `json:"what"`
+
+	// Code and Message describe the error itself.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Stderr and Core are the Manta paths of the standard error output and
+	// core file of the failed task, if any were saved.
+	Stderr string `json:"stderr"`
+	Core   string `json:"core"`
+
+	// Input is the object being processed by the failed task and P0Input
+	// the job input which it was derived from.
+	Input   string `json:"input"`
+	P0Input string `json:"p0input"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Manta reports the phase number as
+// a string, which is converted to an int.
+func (e *JobError) UnmarshalJSON(data []byte) error {
+	type jobError JobError
+	aux := struct {
+		*jobError
+		PhaseNum json.Number `json:"phaseNum"`
+	}{
+		jobError: (*jobError)(e),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.PhaseNum != "" {
+		phase, err := aux.PhaseNum.Int64()
+		if err != nil {
+			return fmt.Errorf("invalid phaseNum %q: %s", aux.PhaseNum, err)
+		}
+		e.Phase = int(phase)
+	}
+
+	return nil
+}
+
+// GetJobErrorsInput represents parameters to a GetJobErrors operation.
+type GetJobErrorsInput struct {
+	JobID string
+}
+
+// GetJobErrorsOutput contains the outputs for a GetJobErrors operation. It is your
+// responsibility to ensure that the io.ReadCloser Items is closed.
+type GetJobErrorsOutput struct {
+	ResultSetSize uint64
+	Items         io.ReadCloser
+}
+
+// GetJobErrors returns the current "live" set of errors from a job as a stream
+// of newline-delimited JSON objects. If error is nil (i.e. the operation is
+// successful), it is your responsibility to close the io.ReadCloser named Items
+// in the output. Use StreamErrors to decode the errors as JobError values.
+func (s *JobClient) GetErrors(ctx context.Context, input *GetJobErrorsInput) (*GetJobErrorsOutput, error) {
+	path := fmt.Sprintf("/%s/jobs/%s/live/err", s.client.AccountName, input.JobID)
+
+	reqInput := client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	respBody, respHeader, err := s.client.ExecuteRequestStorage(ctx, reqInput)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetJobErrors request: {{err}}", err)
+	}
+
+	output := &GetJobErrorsOutput{
+		Items: respBody,
+	}
+
+	resultSetSize, err := strconv.ParseUint(respHeader.Get("Result-Set-Size"), 10, 64)
+	if err == nil {
+		output.ResultSetSize = resultSetSize
+	}
+
+	return output, nil
+}
+
+// JobStreamInput represents parameters to the StreamInputs, StreamOutputs,
+// StreamFailures and StreamErrors operations.
+type JobStreamInput struct {
+	JobID string
+
+	// Follow keeps the stream open while the job is running, polling its
+	// live endpoint for new items until the job is done. Think of this like
+	// `tail -f`.
+	Follow bool
+
+	// PollInterval is the delay between polls when following a job.
+	// Defaults to 5 seconds.
+	PollInterval time.Duration
+}
+
+// StreamInputs returns an iterator over the inputs of a job. When following,
+// the stream ends once the job's input has been closed.
+func (s *JobClient) StreamInputs(ctx context.Context, input *JobStreamInput) *JobLineIterator {
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		output, err := s.GetInput(ctx, &GetJobInputInput{JobID: input.JobID})
+		if err != nil {
+			return nil, err
+		}
+		return output.Items, nil
+	}
+	finished := func(job *Job) bool {
+		return job.InputDone || job.State == JobStateDone
+	}
+	return &JobLineIterator{s.newJobStream(ctx, input, fetch, finished)}
+}
+
+// StreamOutputs returns an iterator over the outputs of a job.
+func (s *JobClient) StreamOutputs(ctx context.Context, input *JobStreamInput) *JobLineIterator {
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		output, err := s.GetOutput(ctx, &GetJobOutputInput{JobID: input.JobID})
+		if err != nil {
+			return nil, err
+		}
+		return output.Items, nil
+	}
+	return &JobLineIterator{s.newJobStream(ctx, input, fetch, jobIsDone)}
+}
+
+// StreamFailures returns an iterator over the inputs of a job which failed.
+func (s *JobClient) StreamFailures(ctx context.Context, input *JobStreamInput) *JobLineIterator {
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		output, err := s.GetFailures(ctx, &GetJobFailuresInput{JobID: input.JobID})
+		if err != nil {
+			return nil, err
+		}
+		return output.Items, nil
+	}
+	return &JobLineIterator{s.newJobStream(ctx, input, fetch, jobIsDone)}
+}
+
+// StreamErrors returns an iterator over the task errors of a job.
+func (s *JobClient) StreamErrors(ctx context.Context, input *JobStreamInput) *JobErrorIterator {
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		output, err := s.GetErrors(ctx, &GetJobErrorsInput{JobID: input.JobID})
+		if err != nil {
+			return nil, err
+		}
+		return output.Items, nil
+	}
+	return &JobErrorIterator{stream: s.newJobStream(ctx, input, fetch, jobIsDone)}
+}
+
+func jobIsDone(job *Job) bool {
+	return job.State == JobStateDone
+}
+
+func (s *JobClient) newJobStream(ctx context.Context, input *JobStreamInput, fetch func(context.Context) (io.ReadCloser, error), finished func(*Job) bool) *jobStream {
+	stream := &jobStream{
+		ctx:   ctx,
+		fetch: fetch,
+	}
+	if input.Follow {
+		stream.pollInterval = input.PollInterval
+		if stream.pollInterval <= 0 {
+			stream.pollInterval = defaultJobPollInterval
+		}
+		stream.finished = func(ctx context.Context) (bool, error) {
+			output, err := s.Get(ctx, &GetJobInput{JobID: input.JobID})
+			if err != nil {
+				return false, err
+			}
+			return finished(output.Job), nil
+		}
+	}
+	return stream
+}
+
+// jobStream reads the newline-delimited items of a job's live endpoint. Each
+// request to a live endpoint returns every item produced so far, so when
+// following a job the items already returned are skipped on each new poll.
+type jobStream struct {
+	ctx   context.Context
+	fetch func(ctx context.Context) (io.ReadCloser, error)
+
+	// finished reports whether the job can still produce items. It is nil
+	// when the stream is not following the job.
+	finished     func(ctx context.Context) (bool, error)
+	pollInterval time.Duration
+
+	reader  io.ReadCloser
+	scanner *bufio.Scanner
+	last    bool
+	done    bool
+	seen    int
+	read    int
+	line    string
+	err     error
+}
+
+func (st *jobStream) next() bool {
+	for {
+		if st.err != nil || st.done {
+			return false
+		}
+
+		if st.scanner == nil {
+			// The job state is checked before fetching, so that items
+			// produced before the job finished are all included in the
+			// final fetch.
+			st.last = true
+			if st.finished != nil {
+				finished, err := st.finished(st.ctx)
+				if err != nil {
+					st.err = err
+					return false
+				}
+				st.last = finished
+			}
+
+			reader, err := st.fetch(st.ctx)
+			if err != nil {
+				st.err = err
+				return false
+			}
+			st.reader = reader
+			st.scanner = bufio.NewScanner(reader)
+			st.scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxJobStreamLineSize)
+			st.read = 0
+		}
+
+		for st.scanner.Scan() {
+			line := strings.TrimSpace(st.scanner.Text())
+			if line == "" {
+				continue
+			}
+			st.read++
+			if st.read <= st.seen {
+				continue
+			}
+			st.seen++
+			st.line = line
+			return true
+		}
+		if err := st.scanner.Err(); err != nil {
+			st.err = err
+			return false
+		}
+
+		st.close()
+		st.scanner = nil
+		if st.last {
+			st.done = true
+			return false
+		}
+
+		select {
+		case <-st.ctx.Done():
+			st.err = st.ctx.Err()
+			return false
+		case <-time.After(st.pollInterval):
+		}
+	}
+}
+
+func (st *jobStream) close() error {
+	if st.reader == nil {
+		return nil
+	}
+	err := st.reader.Close()
+	st.reader = nil
+	return err
+}
+
+// JobLineIterator iterates over the newline-delimited object paths making up
+// the inputs, outputs or failures of a job. No request is made until the first
+// call to Next. It is your responsibility to call Close once you are done with
+// the iterator.
+type JobLineIterator struct {
+	stream *jobStream
+}
+
+// Next advances the iterator to the next object path, which is then available
+// through Path. It returns false when there are no more paths or an error
+// occurred, in which case Err returns the error.
+func (it *JobLineIterator) Next() bool {
+	return it.stream.next()
+}
+
+// Path returns the object path the iterator is positioned on.
+func (it *JobLineIterator) Path() string {
+	return it.stream.line
+}
+
+// Err returns the first error encountered by the iterator, if any.
+func (it *JobLineIterator) Err() error {
+	return it.stream.err
+}
+
+// Close releases the resources held by the iterator.
+func (it *JobLineIterator) Close() error {
+	return it.stream.close()
+}
+
+// JobErrorIterator iterates over the task errors of a job. No request is made
+// until the first call to Next. It is your responsibility to call Close once
+// you are done with the iterator.
+type JobErrorIterator struct {
+	stream  *jobStream
+	current *JobError
+	err     error
+}
+
+// Next advances the iterator to the next task error, which is then available
+// through JobError. It returns false when there are no more errors or an error
+// occurred, in which case Err returns the error.
+func (it *JobErrorIterator) Next() bool {
+	if it.err != nil || !it.stream.next() {
+		return false
+	}
+
+	current := &JobError{}
+	if err := json.Unmarshal([]byte(it.stream.line), current); err != nil {
+		it.err = errwrap.Wrapf("Error decoding job error: {{err}}", err)
+		return false
+	}
+	it.current = current
+	return true
+}
+
+// JobError returns the task error the iterator is positioned on.
+func (it *JobErrorIterator) JobError() *JobError {
+	return it.current
+}
+
+// Err returns the first error encountered by the iterator, if any.
+func (it *JobErrorIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.stream.err
+}
+
+// Close releases the resources held by the iterator.
+func (it *JobErrorIterator) Close() error {
+	return it.stream.close()
+}
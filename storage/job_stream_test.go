@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobStream_Follow(t *testing.T) {
+	polls := []string{
+		"/acct/stor/a\n",
+		"/acct/stor/a\n/acct/stor/b\n",
+		"/acct/stor/a\n/acct/stor/b\n\n/acct/stor/c\n",
+	}
+	fetches := 0
+	stream := &jobStream{
+		ctx: context.Background(),
+		fetch: func(context.Context) (io.ReadCloser, error) {
+			body := polls[fetches]
+			fetches++
+			return ioutil.NopCloser(strings.NewReader(body)), nil
+		},
+		finished: func(context.Context) (bool, error) {
+			return fetches == len(polls)-1, nil
+		},
+		pollInterval: time.Millisecond,
+	}
+	it := &JobLineIterator{stream}
+	defer it.Close()
+
+	var paths []string
+	for it.Next() {
+		paths = append(paths, it.Path())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"/acct/stor/a", "/acct/stor/b", "/acct/stor/c"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+	if fetches != len(polls) {
+		t.Fatalf("expected %d fetches, got %d", len(polls), fetches)
+	}
+	if it.Next() {
+		t.Fatal("expected exhausted iterator to stay exhausted")
+	}
+}
+
+func TestJobErrorIterator(t *testing.T) {
+	body := `{"phaseNum":"1","what":"phase 1: reduce","code":"UserTaskError","message":"user command exited with code 1","stderr":"/acct/jobs/j/stor/reduce.0.err","input":"/acct/stor/x"}
+{"phaseNum":0,"what":"phase 0: map","code":"ResourceNotFoundError","message":"no such object"}
+`
+	stream := &jobStream{
+		ctx: context.Background(),
+		fetch: func(context.Context) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(body)), nil
+		},
+	}
+	it := &JobErrorIterator{stream: stream}
+	defer it.Close()
+
+	var errs []*JobError
+	for it.Next() {
+		errs = append(errs, it.JobError())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(errs))
+	}
+	if errs[0].Phase != 1 || errs[0].Code != "UserTaskError" || errs[0].Stderr != "/acct/jobs/j/stor/reduce.0.err" {
+		t.Fatalf("unexpected first error: %+v", errs[0])
+	}
+	if errs[1].Phase != 0 || errs[1].Message != "no such object" {
+		t.Fatalf("unexpected second error: %+v", errs[1])
+	}
+}
+
+func TestJobError_UnmarshalInvalidPhase(t *testing.T) {
+	jobErr := &JobError{}
+	if err := json.Unmarshal([]byte(`{"phaseNum":"x"}`), jobErr); err == nil {
+		t.Fatal("expected error for non-numeric phaseNum")
+	}
+}
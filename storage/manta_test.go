@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joyent/triton-go/testutils/testclient"
+)
+
+// fakeObject is an object or directory stored by fakeManta.
+type fakeObject struct {
+	dir         bool
+	data        []byte
+	etag        string
+	contentType string
+	metadata    map[string]string
+	headers     map[string]string
+	mtime       time.Time
+}
+
+// fakeObjectHeaders are the headers other than m- metadata which fakeManta
+// stores with objects.
+var fakeObjectHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Durability-Level",
+	"Role-Tag",
+	"Access-Control-Allow-Origin",
+}
+
+// fakeManta is an in-memory Manta serving objects, directories and SnapLinks
+// of the account "acct".
+type fakeManta struct {
+	mu       sync.Mutex
+	objects  map[string]*fakeObject
+	etags    int
+	requests []string
+}
+
+// testStorageClient returns a StorageClient backed by a new fakeManta holding
+// the directory /acct/stor.
+func testStorageClient(t *testing.T) (*StorageClient, *fakeManta) {
+	manta := &fakeManta{
+		objects: map[string]*fakeObject{
+			"/acct/stor": {dir: true},
+		},
+	}
+	return testStorageClientFor(t, manta), manta
+}
+
+// testStorageClientFor returns a StorageClient of the account "acct" whose
+// requests are served by handler.
+func testStorageClientFor(t *testing.T, handler http.Handler) *StorageClient {
+	return newStorageClient(testclient.New(t, handler))
+}
+
+// put stores an object at p, as if uploaded.
+func (m *fakeManta) put(p string, data string) *fakeObject {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.putLocked(p, &fakeObject{data: []byte(data)})
+}
+
+func (m *fakeManta) putLocked(p string, object *fakeObject) *fakeObject {
+	if !object.dir && object.etag == "" {
+		m.etags++
+		object.etag = fmt.Sprintf("etag-%d", m.etags)
+	}
+	object.mtime = time.Date(2017, 8, 1, 0, 0, m.etags, 0, time.UTC)
+	m.objects[p] = object
+	return object
+}
+
+// get returns the object at p, if any.
+func (m *fakeManta) get(p string) *fakeObject {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.objects[p]
+}
+
+func (m *fakeManta) error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"code": code, "message": code})
+}
+
+func (m *fakeManta) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, r.Method+" "+r.URL.Path)
+
+	p := r.URL.Path
+	object := m.objects[p]
+	switch r.Method {
+	case http.MethodPut:
+		if parent := m.objects[path.Dir(p)]; parent == nil || !parent.dir {
+			m.error(w, http.StatusNotFound, "DirectoryDoesNotExist")
+			return
+		}
+		switch r.Header.Get("Content-Type") {
+		case "application/json; type=directory":
+			if object == nil {
+				m.putLocked(p, &fakeObject{dir: true})
+			}
+		case "application/json; type=link":
+			source := m.objects[r.Header.Get("Location")]
+			if source == nil || source.dir {
+				m.error(w, http.StatusNotFound, "SourceObjectNotFound")
+				return
+			}
+			link := *source
+			m.putLocked(p, &link)
+		default:
+			metadata := map[string]string{}
+			for key := range r.Header {
+				if strings.HasPrefix(strings.ToLower(key), "m-") {
+					metadata[key] = r.Header.Get(key)
+				}
+			}
+			headers := map[string]string{}
+			for _, key := range fakeObjectHeaders {
+				if value, ok := r.Header[key]; ok {
+					headers[key] = strings.Join(value, ", ")
+				}
+			}
+
+			// A metadata update replaces every header but keeps the data.
+			if r.URL.Query().Get("metadata") == "true" {
+				if object == nil || object.dir {
+					m.error(w, http.StatusNotFound, "ResourceNotFound")
+					return
+				}
+				object.contentType = r.Header.Get("Content-Type")
+				object.metadata = metadata
+				object.headers = headers
+				break
+			}
+
+			data, _ := ioutil.ReadAll(r.Body)
+			m.putLocked(p, &fakeObject{
+				data:        data,
+				contentType: r.Header.Get("Content-Type"),
+				metadata:    metadata,
+				headers:     headers,
+			})
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodHead, http.MethodGet:
+		if object == nil {
+			m.error(w, http.StatusNotFound, "ResourceNotFound")
+			return
+		}
+		if object.dir {
+			m.list(w, r, p)
+			return
+		}
+		w.Header().Set("Etag", object.etag)
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", object.mtime.Format(time.RFC1123))
+		for key, value := range object.metadata {
+			w.Header().Set(key, value)
+		}
+		for key, value := range object.headers {
+			w.Header().Set(key, value)
+		}
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+
+	case http.MethodDelete:
+		if object == nil {
+			m.error(w, http.StatusNotFound, "ResourceNotFound")
+			return
+		}
+		delete(m.objects, p)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list serves the entries of the directory dir as Manta does, sorted by name
+// and starting at the "marker" entry.
+func (m *fakeManta) list(w http.ResponseWriter, r *http.Request, dir string) {
+	var names []string
+	for p := range m.objects {
+		if path.Dir(p) == dir && p != dir {
+			names = append(names, path.Base(p))
+		}
+	}
+	sort.Strings(names)
+
+	query, _ := url.ParseQuery(r.URL.RawQuery)
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil {
+		limit = 256
+	}
+	marker := query.Get("marker")
+
+	w.Header().Set("Content-Type", "application/x-json-stream; type=directory")
+	w.Header().Set("Result-Set-Size", strconv.Itoa(len(names)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	encoder := json.NewEncoder(w)
+	for _, name := range names {
+		if name < marker {
+			continue
+		}
+		if limit == 0 {
+			break
+		}
+		limit--
+
+		object := m.objects[path.Join(dir, name)]
+		entry := &DirectoryEntry{Name: name, ModifiedTime: object.mtime, Type: "directory"}
+		if !object.dir {
+			entry.Type = "object"
+			entry.ETag = object.etag
+			entry.Size = uint64(len(object.data))
+		}
+		encoder.Encode(entry)
+	}
+}
@@ -33,6 +33,7 @@ type GetObjectOutput struct {
 	ContentMD5    string
 	ETag          string
 	Metadata      map[string]string
+	RoleTags      []string
 	ObjectReader  io.ReadCloser
 }
 
@@ -55,6 +56,7 @@ func (s *ObjectsClient) Get(ctx context.Context, input *GetObjectInput) (*GetObj
 		ContentType:  respHeaders.Get("Content-Type"),
 		ContentMD5:   respHeaders.Get("Content-MD5"),
 		ETag:         respHeaders.Get("Etag"),
+		RoleTags:     parseRoleTagHeader(respHeaders.Get("Role-Tag")),
 		ObjectReader: respBody,
 	}
 
@@ -79,6 +81,67 @@ func (s *ObjectsClient) Get(ctx context.Context, input *GetObjectInput) (*GetObj
 	return response, nil
 }
 
+// GetInfoInput represents parameters to a GetInfo operation.
+type GetInfoInput struct {
+	ObjectPath string
+}
+
+// GetInfoOutput contains the outputs for a GetInfo operation.
+type GetInfoOutput struct {
+	ContentLength uint64
+	ContentType   string
+	LastModified  time.Time
+	ContentMD5    string
+	ETag          string
+	Metadata      map[string]string
+	RoleTags      []string
+}
+
+// GetInfo sends a HEAD request to an object in the Manta service. This function
+// does not return a response body.
+func (s *ObjectsClient) GetInfo(ctx context.Context, input *GetInfoInput) (*GetInfoOutput, error) {
+	path := fmt.Sprintf("/%s%s", s.client.AccountName, input.ObjectPath)
+
+	reqInput := client.RequestInput{
+		Method: http.MethodHead,
+		Path:   path,
+	}
+	respBody, respHeaders, err := s.client.ExecuteRequestStorage(ctx, reqInput)
+	if respBody != nil {
+		defer respBody.Close()
+	}
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetInfo request: {{err}}", err)
+	}
+
+	response := &GetInfoOutput{
+		ContentType: respHeaders.Get("Content-Type"),
+		ContentMD5:  respHeaders.Get("Content-MD5"),
+		ETag:        respHeaders.Get("Etag"),
+		RoleTags:    parseRoleTagHeader(respHeaders.Get("Role-Tag")),
+	}
+
+	lastModified, err := time.Parse(time.RFC1123, respHeaders.Get("Last-Modified"))
+	if err == nil {
+		response.LastModified = lastModified
+	}
+
+	contentLength, err := strconv.ParseUint(respHeaders.Get("Content-Length"), 10, 64)
+	if err == nil {
+		response.ContentLength = contentLength
+	}
+
+	metadata := map[string]string{}
+	for key, values := range respHeaders {
+		if strings.HasPrefix(strings.ToLower(key), "m-") {
+			metadata[key] = strings.Join(values, ", ")
+		}
+	}
+	response.Metadata = metadata
+
+	return response, nil
+}
+
 // DeleteObjectInput represents parameters to a DeleteObject operation.
 type DeleteObjectInput struct {
 	ObjectPath string
@@ -108,6 +171,10 @@ type PutObjectMetadataInput struct {
 	ObjectPath  string
 	ContentType string
 	Metadata    map[string]string
+
+	// RoleTags are the names of the roles given access to the object. As
+	// with the rest of the headers, omitting them removes them.
+	RoleTags []string
 }
 
 // PutObjectMetadata allows you to overwrite the HTTP headers for an already
@@ -129,6 +196,7 @@ func (s *ObjectsClient) PutMetadata(ctx context.Context, input *PutObjectMetadat
 	for key, value := range input.Metadata {
 		headers.Set(key, value)
 	}
+	setRoleTagHeader(headers, input.RoleTags)
 
 	reqInput := client.RequestInput{
 		Method:  http.MethodPut,
@@ -158,6 +226,10 @@ type PutObjectInput struct {
 	ContentLength    uint64
 	MaxContentLength uint64
 	ObjectReader     io.ReadSeeker
+
+	// RoleTags are the names of the roles given access to the object.
+	// Optional.
+	RoleTags []string
 }
 
 func (s *ObjectsClient) Put(ctx context.Context, input *PutObjectInput) error {
@@ -189,6 +261,7 @@ func (s *ObjectsClient) Put(ctx context.Context, input *PutObjectInput) error {
 	if input.MaxContentLength != 0 {
 		headers.Set("Max-Content-Length", strconv.FormatUint(input.MaxContentLength, 10))
 	}
+	setRoleTagHeader(headers, input.RoleTags)
 
 	reqInput := client.RequestNoEncodeInput{
 		Method:  http.MethodPut,
@@ -206,3 +279,55 @@ func (s *ObjectsClient) Put(ctx context.Context, input *PutObjectInput) error {
 
 	return nil
 }
+
+// CopyObjectInput represents parameters to a CopyObject operation.
+type CopyObjectInput struct {
+	SourcePath      string
+	DestinationPath string
+}
+
+// CopyObject copies an object to DestinationPath by creating a SnapLink to it.
+// SnapLinks share the underlying data with their source, so the copy is cheap
+// regardless of the object size and later changes to either path do not affect
+// the other.
+func (s *ObjectsClient) Copy(ctx context.Context, input *CopyObjectInput) error {
+	snapLinks := &SnapLinksClient{s.client}
+	err := snapLinks.Put(ctx, &PutSnapLinkInput{
+		LinkPath:   input.DestinationPath,
+		SourcePath: fmt.Sprintf("/%s%s", s.client.AccountName, input.SourcePath),
+	})
+	if err != nil {
+		return errwrap.Wrapf("Error executing CopyObject request: {{err}}", err)
+	}
+
+	return nil
+}
+
+// MoveObjectInput represents parameters to a MoveObject operation.
+type MoveObjectInput struct {
+	SourcePath      string
+	DestinationPath string
+}
+
+// MoveObject moves (renames) an object by creating a SnapLink at
+// DestinationPath and then deleting SourcePath. Readers of DestinationPath see
+// either the previous object or the complete new one, never a partial write.
+// If the delete fails the object is left reachable under both paths.
+func (s *ObjectsClient) Move(ctx context.Context, input *MoveObjectInput) error {
+	err := s.Copy(ctx, &CopyObjectInput{
+		SourcePath:      input.SourcePath,
+		DestinationPath: input.DestinationPath,
+	})
+	if err != nil {
+		return errwrap.Wrapf("Error executing MoveObject request: {{err}}", err)
+	}
+
+	err = s.Delete(ctx, &DeleteObjectInput{
+		ObjectPath: input.SourcePath,
+	})
+	if err != nil {
+		return errwrap.Wrapf("Error removing source of MoveObject request: {{err}}", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestObjects_GetInfo(t *testing.T) {
+	c, manta := testStorageClient(t)
+	object := manta.put("/acct/stor/config.json", `{"a": 1}`)
+	object.contentType = "application/json"
+	object.metadata = map[string]string{"M-Owner": "ops"}
+
+	info, err := c.Objects().GetInfo(context.Background(), &GetInfoInput{ObjectPath: "/stor/config.json"})
+	if err != nil {
+		t.Fatalf("error getting object info: %s", err)
+	}
+	if info.ETag != object.etag || info.ContentLength != 8 || info.ContentType != "application/json" {
+		t.Fatalf("unexpected object info %+v", info)
+	}
+	if !info.LastModified.Equal(object.mtime.Truncate(time.Second)) {
+		t.Fatalf("unexpected last modified time %s", info.LastModified)
+	}
+	if !reflect.DeepEqual(info.Metadata, map[string]string{"M-Owner": "ops"}) {
+		t.Fatalf("unexpected metadata %v", info.Metadata)
+	}
+
+	if _, err := c.Objects().GetInfo(context.Background(), &GetInfoInput{ObjectPath: "/stor/missing"}); err == nil {
+		t.Fatal("expected error for missing object")
+	}
+}
+
+func TestObjects_CopyMove(t *testing.T) {
+	c, manta := testStorageClient(t)
+	source := manta.put("/acct/stor/a", "data")
+
+	err := c.Objects().Copy(context.Background(), &CopyObjectInput{
+		SourcePath:      "/stor/a",
+		DestinationPath: "/stor/b",
+	})
+	if err != nil {
+		t.Fatalf("error copying object: %s", err)
+	}
+	if copied := manta.get("/acct/stor/b"); copied == nil || copied.etag != source.etag || manta.get("/acct/stor/a") == nil {
+		t.Fatal("expected object to be linked to the destination and kept at the source")
+	}
+
+	err = c.Objects().Move(context.Background(), &MoveObjectInput{
+		SourcePath:      "/stor/b",
+		DestinationPath: "/stor/c",
+	})
+	if err != nil {
+		t.Fatalf("error moving object: %s", err)
+	}
+	if moved := manta.get("/acct/stor/c"); moved == nil || moved.etag != source.etag || manta.get("/acct/stor/b") != nil {
+		t.Fatal("expected object to be moved to the destination")
+	}
+
+	expected := []string{
+		"PUT /acct/stor/b",
+		"PUT /acct/stor/c",
+		"DELETE /acct/stor/b",
+	}
+	if !reflect.DeepEqual(manta.requests, expected) {
+		t.Fatalf("unexpected requests %v", manta.requests)
+	}
+
+	err = c.Objects().Move(context.Background(), &MoveObjectInput{
+		SourcePath:      "/stor/missing",
+		DestinationPath: "/stor/d",
+	})
+	if err == nil {
+		t.Fatal("expected error moving a missing object")
+	}
+}
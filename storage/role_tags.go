@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// RoleTagsClient reads and replaces the role tags of Manta objects and
+// directories, which grant the members of the tagged roles access to them.
+type RoleTagsClient struct {
+	client *client.Client
+}
+
+// parseRoleTagHeader splits the comma-separated value of a Role-Tag header.
+func parseRoleTagHeader(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// setRoleTagHeader sets the Role-Tag header if tags is not nil. An empty,
+// non-nil tags removes every role tag.
+func setRoleTagHeader(headers *http.Header, tags []string) {
+	if tags != nil {
+		headers.Set("Role-Tag", strings.Join(tags, ", "))
+	}
+}
+
+// GetRoleTagsInput represents parameters to a GetRoleTags operation.
+type GetRoleTagsInput struct {
+	// Path is the path of an object or directory, e.g. "/stor/foo".
+	Path string
+}
+
+// Get returns the role tags of an object or directory.
+func (s *RoleTagsClient) Get(ctx context.Context, input *GetRoleTagsInput) ([]string, error) {
+	objClient := &ObjectsClient{s.client}
+	info, err := objClient.GetInfo(ctx, &GetInfoInput{ObjectPath: input.Path})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing GetRoleTags request: {{err}}", err)
+	}
+
+	return info.RoleTags, nil
+}
+
+// ReplaceRoleTagsInput represents parameters to a ReplaceRoleTags operation.
+type ReplaceRoleTagsInput struct {
+	// Path is the path of an object or directory, e.g. "/stor/foo".
+	Path string
+
+	// RoleTags are the names of the roles given access. An empty list
+	// removes every role tag.
+	RoleTags []string
+}
+
+// preservedObjectHeaders are the headers, besides m- metadata, which Manta
+// lets a metadata update set, and which Replace therefore carries over.
+var preservedObjectHeaders = []string{
+	"Content-Type",
+	"Content-Disposition",
+	"Cache-Control",
+	"Durability-Level",
+}
+
+// Replace replaces every role tag of an object or directory. Manta replaces
+// every header of an object when its metadata is updated, so the content type,
+// disposition, cache control, durability level, CORS headers and m- metadata
+// of objects are carried over.
+func (s *RoleTagsClient) Replace(ctx context.Context, input *ReplaceRoleTagsInput) error {
+	roleTags := input.RoleTags
+	if roleTags == nil {
+		roleTags = []string{}
+	}
+
+	path := fmt.Sprintf("/%s%s", s.client.AccountName, input.Path)
+	respBody, respHeaders, err := s.client.ExecuteRequestStorage(ctx, client.RequestInput{
+		Method: http.MethodHead,
+		Path:   path,
+	})
+	if respBody != nil {
+		respBody.Close()
+	}
+	if err != nil {
+		return errwrap.Wrapf("Error executing ReplaceRoleTags request: {{err}}", err)
+	}
+
+	if strings.Contains(respHeaders.Get("Content-Type"), "type=directory") {
+		dirClient := &DirectoryClient{s.client}
+		err = dirClient.Put(ctx, &PutDirectoryInput{
+			DirectoryName: input.Path,
+			RoleTags:      roleTags,
+		})
+		if err != nil {
+			return errwrap.Wrapf("Error executing ReplaceRoleTags request: {{err}}", err)
+		}
+		return nil
+	}
+
+	headers := &http.Header{}
+	for _, key := range preservedObjectHeaders {
+		if value := respHeaders.Get(key); value != "" {
+			headers.Set(key, value)
+		}
+	}
+	for key, values := range respHeaders {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "m-") || strings.HasPrefix(lower, "access-control-") {
+			headers.Set(key, strings.Join(values, ", "))
+		}
+	}
+	setRoleTagHeader(headers, roleTags)
+
+	query := &url.Values{}
+	query.Set("metadata", "true")
+	respBody, _, err = s.client.ExecuteRequestStorage(ctx, client.RequestInput{
+		Method:  http.MethodPut,
+		Path:    path,
+		Query:   query,
+		Headers: headers,
+	})
+	if respBody != nil {
+		defer respBody.Close()
+	}
+	if err != nil {
+		return errwrap.Wrapf("Error executing ReplaceRoleTags request: {{err}}", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseRoleTagHeader(t *testing.T) {
+	cases := map[string][]string{
+		"":                 {},
+		"ops":              {"ops"},
+		"ops, dev,,admin ": {"ops", "dev", "admin"},
+	}
+	for header, expected := range cases {
+		if got := parseRoleTagHeader(header); !reflect.DeepEqual(got, expected) {
+			t.Errorf("parseRoleTagHeader(%q) = %v, want %v", header, got, expected)
+		}
+	}
+}
+
+func TestSetRoleTagHeader(t *testing.T) {
+	headers := &http.Header{}
+	setRoleTagHeader(headers, nil)
+	if _, ok := (*headers)["Role-Tag"]; ok {
+		t.Error("expected no Role-Tag header for nil tags")
+	}
+
+	setRoleTagHeader(headers, []string{})
+	if values, ok := (*headers)["Role-Tag"]; !ok || values[0] != "" {
+		t.Errorf("expected empty Role-Tag header, got %v", values)
+	}
+
+	setRoleTagHeader(headers, []string{"ops", "dev"})
+	if got := headers.Get("Role-Tag"); got != "ops, dev" {
+		t.Errorf("expected %q, got %q", "ops, dev", got)
+	}
+}
+
+func TestRoleTagsReplace(t *testing.T) {
+	c, manta := testStorageClient(t)
+	manta.putLocked("/acct/stor/report.csv", &fakeObject{
+		data:        []byte("a,b\n"),
+		contentType: "text/csv",
+		metadata:    map[string]string{"M-Owner": "ops"},
+		headers: map[string]string{
+			"Cache-Control":               "max-age=60",
+			"Durability-Level":            "3",
+			"Access-Control-Allow-Origin": "*",
+			"Role-Tag":                    "old",
+		},
+	})
+
+	ctx := context.Background()
+	err := c.RoleTags().Replace(ctx, &ReplaceRoleTagsInput{
+		Path:     "/stor/report.csv",
+		RoleTags: []string{"ops", "dev"},
+	})
+	if err != nil {
+		t.Fatalf("error replacing role tags: %s", err)
+	}
+
+	tags, err := c.RoleTags().Get(ctx, &GetRoleTagsInput{Path: "/stor/report.csv"})
+	if err != nil {
+		t.Fatalf("error getting role tags: %s", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"ops", "dev"}) {
+		t.Fatalf("unexpected role tags %v", tags)
+	}
+
+	object := manta.get("/acct/stor/report.csv")
+	if string(object.data) != "a,b\n" || object.contentType != "text/csv" {
+		t.Fatalf("expected data and content type to be kept, got %q, %q", object.data, object.contentType)
+	}
+	if object.metadata["M-Owner"] != "ops" {
+		t.Fatalf("expected metadata to be kept, got %v", object.metadata)
+	}
+	for key, value := range map[string]string{
+		"Cache-Control":               "max-age=60",
+		"Durability-Level":            "3",
+		"Access-Control-Allow-Origin": "*",
+	} {
+		if object.headers[key] != value {
+			t.Errorf("expected %s %q to be kept, got %q", key, value, object.headers[key])
+		}
+	}
+}
+
+func TestRoleTagsReplace_Directory(t *testing.T) {
+	c, manta := testStorageClient(t)
+	manta.putLocked("/acct/stor/reports", &fakeObject{dir: true})
+
+	err := c.RoleTags().Replace(context.Background(), &ReplaceRoleTagsInput{Path: "/stor/reports"})
+	if err != nil {
+		t.Fatalf("error replacing role tags: %s", err)
+	}
+	last := manta.requests[len(manta.requests)-1]
+	if last != "PUT /acct/stor/reports" {
+		t.Fatalf("expected the directory to be put again, got %s", last)
+	}
+}
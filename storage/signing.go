@@ -2,13 +2,17 @@ package storage
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/authentication"
+	"golang.org/x/crypto/ssh"
 )
 
 // SignURLInput represents parameters to a SignURL operation.
@@ -16,6 +20,19 @@ type SignURLInput struct {
 	ValidityPeriod time.Duration
 	Method         string
 	ObjectPath     string
+
+	// DirectoryPath signs a listing of a directory rather than an object.
+	// Only one of ObjectPath and DirectoryPath may be set.
+	DirectoryPath string
+
+	// Query holds additional query parameters, such as "role-tag" or
+	// "metadata", which are covered by the signature and included in the
+	// signed URL. Optional.
+	Query url.Values
+
+	// ExpiresAt sets an absolute expiry time for the URL. If set, it takes
+	// precedence over ValidityPeriod.
+	ExpiresAt time.Time
 }
 
 // SignURLOutput contains the outputs of a SignURL operation. To simply
@@ -23,20 +40,19 @@ type SignURLInput struct {
 type SignURLOutput struct {
 	host       string
 	objectPath string
+	query      url.Values
 	Method     string
 	Algorithm  string
 	Signature  string
 	Expires    string
 	KeyID      string
+	ExpiresAt  time.Time
 }
 
 // SignedURL returns a signed URL for the given scheme. Valid schemes are
 // `http` and `https`.
 func (output *SignURLOutput) SignedURL(scheme string) string {
-	query := &url.Values{}
-	query.Set("algorithm", output.Algorithm)
-	query.Set("expires", output.Expires)
-	query.Set("keyId", output.KeyID)
+	query := signingQuery(output.query, output.Algorithm, output.Expires, output.KeyID)
 	query.Set("signature", output.Signature)
 
 	sUrl := url.URL{}
@@ -51,27 +67,39 @@ func (output *SignURLOutput) SignedURL(scheme string) string {
 // SignURL creates a time-expiring URL that can be shared with others.
 // This is useful to generate HTML links, for example.
 func (s *StorageClient) SignURL(input *SignURLInput) (*SignURLOutput, error) {
+	if input.ObjectPath != "" && input.DirectoryPath != "" {
+		return nil, errors.New("ObjectPath and DirectoryPath may not both be set")
+	}
+	resourcePath := input.ObjectPath
+	if input.DirectoryPath != "" {
+		resourcePath = input.DirectoryPath
+	}
+
+	method := input.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	expiresAt := input.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(input.ValidityPeriod)
+	}
+
 	output := &SignURLOutput{
 		host:       s.Client.MantaURL.Host,
-		objectPath: fmt.Sprintf("/%s%s", s.Client.AccountName, input.ObjectPath),
-		Method:     input.Method,
+		objectPath: fmt.Sprintf("/%s%s", s.Client.AccountName, resourcePath),
+		query:      input.Query,
+		Method:     method,
 		Algorithm:  strings.ToUpper(s.Client.Authorizers[0].DefaultAlgorithm()),
-		Expires:    strconv.FormatInt(time.Now().Add(input.ValidityPeriod).Unix(), 10),
-		KeyID:      fmt.Sprintf("/%s/keys/%s", s.Client.AccountName, s.Client.Authorizers[0].KeyFingerprint()),
+		Expires:    strconv.FormatInt(expiresAt.Unix(), 10),
+		KeyID:      authentication.KeyID(s.Client.AccountName, s.Client.Username, s.Client.Authorizers[0].KeyFingerprint(), true),
+		ExpiresAt:  time.Unix(expiresAt.Unix(), 0),
 	}
 
-	toSign := bytes.Buffer{}
-	toSign.WriteString(input.Method + "\n")
-	toSign.WriteString(s.Client.MantaURL.Host + "\n")
-	toSign.WriteString(fmt.Sprintf("/%s%s\n", s.Client.AccountName, input.ObjectPath))
-
-	query := &url.Values{}
-	query.Set("algorithm", output.Algorithm)
-	query.Set("expires", output.Expires)
-	query.Set("keyId", output.KeyID)
-	toSign.WriteString(query.Encode())
+	query := signingQuery(output.query, output.Algorithm, output.Expires, output.KeyID)
+	toSign := stringToSign(method, output.host, output.objectPath, query)
 
-	signature, _, err := s.Client.Authorizers[0].SignRaw(toSign.String())
+	signature, _, err := s.Client.Authorizers[0].SignRaw(toSign)
 	if err != nil {
 		return nil, errwrap.Wrapf("Error signing string: {{err}}", err)
 	}
@@ -79,3 +107,118 @@ func (s *StorageClient) SignURL(input *SignURLInput) (*SignURLOutput, error) {
 	output.Signature = signature
 	return output, nil
 }
+
+// signingQuery returns the query parameters covered by the signature of a
+// signed URL.
+func signingQuery(extra url.Values, algorithm, expires, keyID string) url.Values {
+	query := url.Values{}
+	for key, values := range extra {
+		query[key] = append([]string(nil), values...)
+	}
+	query.Set("algorithm", algorithm)
+	query.Set("expires", expires)
+	query.Set("keyId", keyID)
+	return query
+}
+
+// stringToSign builds the string signed for a signed URL. Encode sorts the
+// query parameters by key, as Manta requires.
+func stringToSign(method, host, path string, query url.Values) string {
+	toSign := bytes.Buffer{}
+	toSign.WriteString(method + "\n")
+	toSign.WriteString(host + "\n")
+	toSign.WriteString(path + "\n")
+	toSign.WriteString(query.Encode())
+	return toSign.String()
+}
+
+// VerifySignedURLInput represents parameters to a VerifySignedURL operation.
+type VerifySignedURLInput struct {
+	// SignedURL is the full URL to verify, as returned by SignedURL.
+	SignedURL string
+
+	// Method is the HTTP method the URL is used with. Defaults to GET.
+	Method string
+
+	// PublicKey is the public half of the key the URL claims to be signed
+	// with, e.g. as parsed by ssh.ParseAuthorizedKey.
+	PublicKey ssh.PublicKey
+
+	// Now overrides the time the expiry is checked against. Optional.
+	Now time.Time
+}
+
+// VerifySignedURLOutput contains the outputs of a VerifySignedURL operation.
+type VerifySignedURLOutput struct {
+	// Valid is true if the signature matches and the URL has not expired.
+	Valid bool
+
+	// SignatureValid is true if the signature matches, regardless of expiry.
+	SignatureValid bool
+
+	Expired   bool
+	ExpiresAt time.Time
+
+	// ExpiresIn is the time remaining until the URL expires. It is negative
+	// once the URL has expired.
+	ExpiresIn time.Duration
+
+	KeyID string
+	Path  string
+	Query url.Values
+}
+
+// VerifySignedURL checks a signed URL against a public key and reports whether
+// it is valid and how long until it expires. An error is only returned if the
+// URL is malformed; a bad signature or an expired URL are reported in the
+// output.
+func VerifySignedURL(input *VerifySignedURLInput) (*VerifySignedURLOutput, error) {
+	if input.PublicKey == nil {
+		return nil, errors.New("PublicKey can not be empty")
+	}
+
+	sUrl, err := url.Parse(input.SignedURL)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error parsing signed URL: {{err}}", err)
+	}
+
+	query := sUrl.Query()
+	signature := query.Get("signature")
+	algorithm := query.Get("algorithm")
+	expires := query.Get("expires")
+	keyID := query.Get("keyId")
+	if signature == "" || algorithm == "" || expires == "" || keyID == "" {
+		return nil, errors.New("Signed URL must contain signature, algorithm, expires and keyId parameters")
+	}
+	query.Del("signature")
+
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error parsing expires parameter: {{err}}", err)
+	}
+
+	method := input.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	now := input.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	output := &VerifySignedURLOutput{
+		ExpiresAt: time.Unix(expiresUnix, 0),
+		KeyID:     keyID,
+		Path:      sUrl.Path,
+		Query:     query,
+	}
+	output.ExpiresIn = output.ExpiresAt.Sub(now)
+	output.Expired = output.ExpiresIn <= 0
+
+	toVerify := stringToSign(method, sUrl.Host, sUrl.Path, query)
+	err = authentication.Verify(input.PublicKey, algorithm, toVerify, signature)
+	output.SignatureValid = err == nil
+	output.Valid = output.SignatureValid && !output.Expired
+
+	return output, nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joyent/triton-go/authentication"
+	"github.com/joyent/triton-go/client"
+	"golang.org/x/crypto/ssh"
+)
+
+func testSigningClient(t *testing.T, userName string) (*StorageClient, ssh.PublicKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %s", err)
+	}
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("error converting public key: %s", err)
+	}
+	material := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	fingerprint := strings.TrimPrefix(ssh.FingerprintLegacyMD5(publicKey), "MD5:")
+	signer, err := authentication.NewPrivateKeySigner(fingerprint, material, "acct")
+	if err != nil {
+		t.Fatalf("error creating signer: %s", err)
+	}
+
+	mantaURL, _ := url.Parse("https://us-east.manta.joyent.com")
+	return &StorageClient{
+		Client: &client.Client{
+			MantaURL:    *mantaURL,
+			AccountName: "acct",
+			Username:    userName,
+			Authorizers: []authentication.Signer{signer},
+		},
+	}, publicKey
+}
+
+func TestSignURL_Verify(t *testing.T) {
+	c, publicKey := testSigningClient(t, "")
+	expiresAt := time.Now().Add(time.Hour)
+
+	output, err := c.SignURL(&SignURLInput{
+		ObjectPath: "/stor/uploads/a file.txt",
+		Method:     "PUT",
+		ExpiresAt:  expiresAt,
+		Query: url.Values{
+			"role-tag": []string{"uploaders"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error signing URL: %s", err)
+	}
+	signedURL := output.SignedURL("https")
+	if !strings.Contains(signedURL, "role-tag=uploaders") {
+		t.Fatalf("expected role-tag in signed URL: %s", signedURL)
+	}
+
+	verified, err := VerifySignedURL(&VerifySignedURLInput{
+		SignedURL: signedURL,
+		Method:    "PUT",
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		t.Fatalf("error verifying URL: %s", err)
+	}
+	if !verified.Valid {
+		t.Fatal("expected URL to be valid")
+	}
+	if verified.ExpiresAt.Unix() != expiresAt.Unix() {
+		t.Fatalf("expected expiry %s, got %s", expiresAt, verified.ExpiresAt)
+	}
+	if verified.ExpiresIn <= 0 || verified.ExpiresIn > time.Hour {
+		t.Fatalf("unexpected ExpiresIn: %s", verified.ExpiresIn)
+	}
+
+	verified, err = VerifySignedURL(&VerifySignedURLInput{
+		SignedURL: signedURL,
+		Method:    "GET",
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		t.Fatalf("error verifying URL: %s", err)
+	}
+	if verified.Valid || verified.SignatureValid {
+		t.Fatal("expected URL signed for PUT to be invalid for GET")
+	}
+
+	tampered := strings.Replace(signedURL, "role-tag=uploaders", "role-tag=admins", 1)
+	verified, err = VerifySignedURL(&VerifySignedURLInput{
+		SignedURL: tampered,
+		Method:    "PUT",
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		t.Fatalf("error verifying URL: %s", err)
+	}
+	if verified.Valid {
+		t.Fatal("expected tampered URL to be invalid")
+	}
+
+	verified, err = VerifySignedURL(&VerifySignedURLInput{
+		SignedURL: signedURL,
+		Method:    "PUT",
+		PublicKey: publicKey,
+		Now:       expiresAt.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("error verifying URL: %s", err)
+	}
+	if verified.Valid || !verified.SignatureValid || !verified.Expired {
+		t.Fatalf("expected expired URL with valid signature, got %+v", verified)
+	}
+}
+
+func TestSignURL_Directory(t *testing.T) {
+	c, publicKey := testSigningClient(t, "")
+
+	if _, err := c.SignURL(&SignURLInput{ObjectPath: "/stor/a", DirectoryPath: "/stor"}); err == nil {
+		t.Fatal("expected error when both ObjectPath and DirectoryPath are set")
+	}
+
+	output, err := c.SignURL(&SignURLInput{
+		DirectoryPath:  "/stor/reports",
+		ValidityPeriod: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("error signing URL: %s", err)
+	}
+	if output.Method != "GET" {
+		t.Fatalf("expected default method GET, got %s", output.Method)
+	}
+
+	verified, err := VerifySignedURL(&VerifySignedURLInput{
+		SignedURL: output.SignedURL("https"),
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		t.Fatalf("error verifying URL: %s", err)
+	}
+	if !verified.Valid || verified.Path != "/acct/stor/reports" {
+		t.Fatalf("unexpected verification result: %+v", verified)
+	}
+}
+
+func TestSignURL_SubUser(t *testing.T) {
+	c, publicKey := testSigningClient(t, "ci")
+
+	output, err := c.SignURL(&SignURLInput{
+		ObjectPath:     "/stor/artifact.tgz",
+		ValidityPeriod: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("error signing URL: %s", err)
+	}
+	if !strings.HasPrefix(output.KeyID, "/acct/ci/keys/") {
+		t.Fatalf("expected sub-user keyId, got %q", output.KeyID)
+	}
+
+	if _, err := VerifySignedURL(&VerifySignedURLInput{
+		SignedURL: output.SignedURL("https"),
+		Method:    "GET",
+		PublicKey: publicKey,
+	}); err != nil {
+		t.Fatalf("error verifying signed URL: %s", err)
+	}
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// UsageClient reports on the storage consumed by an account, either by walking
+// a directory tree or from the usage reports Manta writes into the account.
+type UsageClient struct {
+	client *client.Client
+}
+
+const (
+	// defaultDurabilityLevel is the number of copies Manta keeps of an
+	// object when no durability level was requested.
+	defaultDurabilityLevel = 2
+
+	defaultUsageReportsDirectory = "/reports/usage/storage"
+
+	UsageSourceWalk   = "walk"
+	UsageSourceReport = "report"
+)
+
+// PrefixUsage totals the storage used beneath a path prefix.
+type PrefixUsage struct {
+	Prefix      string `json:"prefix"`
+	Directories uint64 `json:"directories"`
+	Objects     uint64 `json:"objects"`
+	Bytes       uint64 `json:"bytes"`
+
+	// DurabilityBytes is the number of bytes stored once every copy kept
+	// by Manta is accounted for, i.e. Bytes weighted by durability level.
+	DurabilityBytes uint64 `json:"durability_bytes"`
+}
+
+func (u *PrefixUsage) add(other *PrefixUsage) {
+	u.Directories += other.Directories
+	u.Objects += other.Objects
+	u.Bytes += other.Bytes
+	u.DurabilityBytes += other.DurabilityBytes
+}
+
+// UsageReport contains the storage usage of an account, broken down by prefix.
+type UsageReport struct {
+	// Root is the directory the report covers.
+	Root string `json:"root"`
+
+	// Source is either UsageSourceWalk or UsageSourceReport.
+	Source string `json:"source"`
+
+	// GeneratedAt is the time the usage was measured.
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Prefixes []*PrefixUsage `json:"prefixes"`
+	Total    *PrefixUsage   `json:"total"`
+}
+
+// WriteJSON writes the report to w as indented JSON.
+func (r *UsageReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(r)
+}
+
+// WriteTable writes the report to w as an aligned text table, one row per
+// prefix followed by the total.
+func (r *UsageReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "PREFIX\tDIRECTORIES\tOBJECTS\tBYTES\tDURABILITY BYTES\t")
+	rows := append([]*PrefixUsage{}, r.Prefixes...)
+	if r.Total != nil {
+		total := *r.Total
+		total.Prefix = "TOTAL"
+		rows = append(rows, &total)
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t\n", row.Prefix, row.Directories,
+			row.Objects, row.Bytes, row.DurabilityBytes)
+	}
+	return tw.Flush()
+}
+
+// WalkUsageInput represents parameters to a WalkUsage operation.
+type WalkUsageInput struct {
+	// DirectoryName is the root of the tree to measure, e.g. "/stor".
+	DirectoryName string
+
+	// Depth is the number of directory levels beneath DirectoryName used to
+	// group usage. A Depth of 1 reports each immediate subdirectory
+	// separately, while 0 reports only the total.
+	Depth int
+}
+
+// WalkUsage measures the storage used beneath a directory by listing every
+// entry in the tree. Listings report the durability level of each object, so
+// durability-weighted totals are exact. This makes one request per directory.
+func (s *UsageClient) Walk(ctx context.Context, input *WalkUsageInput) (*UsageReport, error) {
+	root := strings.TrimSuffix(input.DirectoryName, "/")
+	prefixes := map[string]*PrefixUsage{}
+
+	dirClient := &DirectoryClient{s.client}
+	err := dirClient.Walk(ctx, &WalkDirectoryInput{DirectoryName: root}, func(entryPath string, entry *DirectoryEntry) error {
+		prefix := usagePrefix(root, entryPath, input.Depth)
+		usage, ok := prefixes[prefix]
+		if !ok {
+			usage = &PrefixUsage{Prefix: prefix}
+			prefixes[prefix] = usage
+		}
+
+		if entry.Type == "directory" {
+			usage.Directories++
+			return nil
+		}
+
+		durability := entry.Durability
+		if durability == 0 {
+			durability = defaultDurabilityLevel
+		}
+		usage.Objects++
+		usage.Bytes += entry.Size
+		usage.DurabilityBytes += entry.Size * durability
+		return nil
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing WalkUsage request: {{err}}", err)
+	}
+
+	return newUsageReport(root, UsageSourceWalk, time.Now().UTC(), prefixes), nil
+}
+
+// usagePrefix returns the prefix an entry is accounted under: the first depth
+// directories of its path beneath root.
+func usagePrefix(root, entryPath string, depth int) string {
+	relative := strings.TrimPrefix(entryPath, root+"/")
+	dirs := strings.Split(path.Dir(relative), "/")
+	if dirs[0] == "." {
+		dirs = nil
+	}
+	if len(dirs) > depth {
+		dirs = dirs[:depth]
+	}
+	if len(dirs) == 0 {
+		return root
+	}
+	return root + "/" + strings.Join(dirs, "/")
+}
+
+func newUsageReport(root, source string, generatedAt time.Time, prefixes map[string]*PrefixUsage) *UsageReport {
+	report := &UsageReport{
+		Root:        root,
+		Source:      source,
+		GeneratedAt: generatedAt,
+		Prefixes:    make([]*PrefixUsage, 0, len(prefixes)),
+		Total:       &PrefixUsage{Prefix: root},
+	}
+	for _, usage := range prefixes {
+		report.Prefixes = append(report.Prefixes, usage)
+		report.Total.add(usage)
+	}
+	sort.Slice(report.Prefixes, func(i, j int) bool {
+		return report.Prefixes[i].Prefix < report.Prefixes[j].Prefix
+	})
+	return report
+}
+
+// ReportUsageInput represents parameters to a ReportUsage operation.
+type ReportUsageInput struct {
+	// ReportsDirectory overrides the directory Manta writes storage usage
+	// reports to. Defaults to "/reports/usage/storage".
+	ReportsDirectory string
+
+	// DurabilityLevel is the durability level assumed when computing
+	// DurabilityBytes, as usage reports do not break usage down by
+	// durability. Defaults to 2.
+	DurabilityLevel uint64
+}
+
+// reportCount is a count from a usage report, which Manta may encode either
+// as a JSON number or as a string.
+type reportCount uint64
+
+func (c *reportCount) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" {
+		*c = 0
+		return nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid count %s in usage report", data)
+	}
+	*c = reportCount(value)
+	return nil
+}
+
+type storageUsageReport struct {
+	Storage map[string]struct {
+		Directories reportCount `json:"directories"`
+		Keys        reportCount `json:"keys"`
+		Objects     reportCount `json:"objects"`
+		Bytes       reportCount `json:"bytes"`
+	} `json:"storage"`
+}
+
+// ReportUsage reads the most recent hourly storage usage report Manta has
+// written into the account, which is much cheaper than walking the tree but
+// only breaks usage down by top-level directory.
+func (s *UsageClient) Report(ctx context.Context, input *ReportUsageInput) (*UsageReport, error) {
+	reportsDir := input.ReportsDirectory
+	if reportsDir == "" {
+		reportsDir = defaultUsageReportsDirectory
+	}
+	durability := input.DurabilityLevel
+	if durability == 0 {
+		durability = defaultDurabilityLevel
+	}
+
+	// Reports are stored as YYYY/MM/DD/HH/hHH.json, so the latest one is
+	// found by following the greatest name at each level.
+	dirClient := &DirectoryClient{s.client}
+	current := strings.TrimSuffix(reportsDir, "/")
+	var latest *DirectoryEntry
+	for level := 0; level < 5; level++ {
+		entries, err := dirClient.listAll(ctx, current)
+		if err != nil {
+			return nil, errwrap.Wrapf("Error executing ReportUsage request: {{err}}", err)
+		}
+
+		wantType := "directory"
+		if level == 4 {
+			wantType = "object"
+		}
+		latest = nil
+		for _, entry := range entries {
+			if entry.Type == wantType && (latest == nil || entry.Name > latest.Name) {
+				latest = entry
+			}
+		}
+		if latest == nil {
+			return nil, fmt.Errorf("Error executing ReportUsage request: no usage report found in %s", current)
+		}
+		current = current + "/" + latest.Name
+	}
+
+	objClient := &ObjectsClient{s.client}
+	object, err := objClient.Get(ctx, &GetObjectInput{ObjectPath: current})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ReportUsage request: {{err}}", err)
+	}
+	defer object.ObjectReader.Close()
+
+	var raw storageUsageReport
+	decoder := json.NewDecoder(object.ObjectReader)
+	if err = decoder.Decode(&raw); err != nil {
+		return nil, errwrap.Wrapf("Error decoding ReportUsage response: {{err}}", err)
+	}
+
+	prefixes := make(map[string]*PrefixUsage, len(raw.Storage))
+	for name, counts := range raw.Storage {
+		prefix := "/" + name
+		prefixes[prefix] = &PrefixUsage{
+			Prefix:          prefix,
+			Directories:     uint64(counts.Directories),
+			Objects:         uint64(counts.Objects),
+			Bytes:           uint64(counts.Bytes),
+			DurabilityBytes: uint64(counts.Bytes) * durability,
+		}
+	}
+
+	return newUsageReport("/", UsageSourceReport, latest.ModifiedTime, prefixes), nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsagePrefix(t *testing.T) {
+	cases := []struct {
+		path     string
+		depth    int
+		expected string
+	}{
+		{"/stor/a.txt", 1, "/stor"},
+		{"/stor/logs", 1, "/stor"},
+		{"/stor/logs/a.log", 1, "/stor/logs"},
+		{"/stor/logs/2017/a.log", 1, "/stor/logs"},
+		{"/stor/logs/2017/a.log", 2, "/stor/logs/2017"},
+		{"/stor/logs/2017/a.log", 0, "/stor"},
+	}
+	for _, c := range cases {
+		if actual := usagePrefix("/stor", c.path, c.depth); actual != c.expected {
+			t.Errorf("usagePrefix(%q, %d): expected %q, got %q", c.path, c.depth, c.expected, actual)
+		}
+	}
+}
+
+func TestUsageReport_Render(t *testing.T) {
+	report := newUsageReport("/stor", UsageSourceWalk, time.Date(2017, 8, 1, 0, 0, 0, 0, time.UTC), map[string]*PrefixUsage{
+		"/stor/b": {Prefix: "/stor/b", Objects: 1, Bytes: 10, DurabilityBytes: 30},
+		"/stor/a": {Prefix: "/stor/a", Directories: 1, Objects: 2, Bytes: 5, DurabilityBytes: 10},
+	})
+	if report.Prefixes[0].Prefix != "/stor/a" {
+		t.Fatalf("expected prefixes sorted, got %s first", report.Prefixes[0].Prefix)
+	}
+	if report.Total.Objects != 3 || report.Total.Bytes != 15 || report.Total.DurabilityBytes != 40 {
+		t.Fatalf("unexpected total: %+v", report.Total)
+	}
+
+	table := &bytes.Buffer{}
+	if err := report.WriteTable(table); err != nil {
+		t.Fatalf("error writing table: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[3], "TOTAL") {
+		t.Fatalf("unexpected table:\n%s", table)
+	}
+
+	out := &bytes.Buffer{}
+	if err := report.WriteJSON(out); err != nil {
+		t.Fatalf("error writing JSON: %s", err)
+	}
+	decoded := &UsageReport{}
+	if err := json.Unmarshal(out.Bytes(), decoded); err != nil {
+		t.Fatalf("error decoding JSON: %s", err)
+	}
+	if decoded.Total.DurabilityBytes != 40 || len(decoded.Prefixes) != 2 {
+		t.Fatalf("unexpected decoded report: %+v", decoded)
+	}
+}
+
+func TestReportCount_Unmarshal(t *testing.T) {
+	var raw storageUsageReport
+	body := `{"storage":{"stor":{"directories":"3","objects":4,"bytes":"1024"}}}`
+	if err := json.Unmarshal([]byte(body), &raw); err != nil {
+		t.Fatalf("error decoding report: %s", err)
+	}
+	stor := raw.Storage["stor"]
+	if stor.Directories != 3 || stor.Objects != 4 || stor.Bytes != 1024 {
+		t.Fatalf("unexpected counts: %+v", stor)
+	}
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/joyent/triton-go/client"
+)
+
+// VersionsClient stores each upload of an object under a timestamped path and
+// points the object's stable path at the newest upload with a SnapLink. Since
+// the SnapLink is replaced in a single request, readers of the stable path
+// switch from one version to the next atomically.
+type VersionsClient struct {
+	client *client.Client
+}
+
+// versionNameFormat is sortable, so the lexical order of version names matches
+// the order in which they were written.
+const versionNameFormat = "20060102T150405.000000000Z"
+
+// ObjectVersion represents a single stored version of a versioned object.
+type ObjectVersion struct {
+	// Version is the name of the version within the versions directory.
+	Version string
+
+	// Path is the full path of the version, relative to the account root.
+	Path string
+
+	ETag         string
+	Size         uint64
+	ModifiedTime time.Time
+
+	// Current indicates the version the stable path currently links to.
+	Current bool
+}
+
+// versionsDir returns the directory holding the versions of objectPath. Unless
+// overridden, versions are stored next to the object in "<object>.versions".
+func versionsDir(objectPath, dir string) string {
+	if dir != "" {
+		return dir
+	}
+	return objectPath + ".versions"
+}
+
+// PutVersionInput represents parameters to a PutVersion operation.
+type PutVersionInput struct {
+	// ObjectPath is the stable path readers use to access the object.
+	ObjectPath string
+
+	// VersionsDir overrides the directory versions are written to. Optional.
+	VersionsDir string
+
+	DurabilityLevel uint64
+	ContentType     string
+	ContentLength   uint64
+	ObjectReader    io.ReadSeeker
+}
+
+// PutVersion uploads a new version of an object and then links ObjectPath to
+// it. The previous versions remain available for List and Rollback.
+func (s *VersionsClient) Put(ctx context.Context, input *PutVersionInput) (*ObjectVersion, error) {
+	dir := versionsDir(input.ObjectPath, input.VersionsDir)
+	dirClient := &DirectoryClient{s.client}
+	err := dirClient.Put(ctx, &PutDirectoryInput{
+		DirectoryName: dir,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PutVersion request: {{err}}", err)
+	}
+
+	version := time.Now().UTC().Format(versionNameFormat)
+	versionPath := fmt.Sprintf("%s/%s", dir, version)
+
+	objClient := &ObjectsClient{s.client}
+	err = objClient.Put(ctx, &PutObjectInput{
+		ObjectPath:      versionPath,
+		DurabilityLevel: input.DurabilityLevel,
+		ContentType:     input.ContentType,
+		ContentLength:   input.ContentLength,
+		ObjectReader:    input.ObjectReader,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing PutVersion request: {{err}}", err)
+	}
+
+	if err := s.link(ctx, input.ObjectPath, versionPath); err != nil {
+		return nil, errwrap.Wrapf("Error executing PutVersion request: {{err}}", err)
+	}
+
+	return &ObjectVersion{
+		Version: version,
+		Path:    versionPath,
+		Current: true,
+	}, nil
+}
+
+// ListVersionsInput represents parameters to a ListVersions operation.
+type ListVersionsInput struct {
+	ObjectPath  string
+	VersionsDir string
+}
+
+// ListVersions returns every stored version of an object, newest first. The
+// version ObjectPath currently links to is marked as Current.
+func (s *VersionsClient) List(ctx context.Context, input *ListVersionsInput) ([]*ObjectVersion, error) {
+	versions, err := s.list(ctx, input.ObjectPath, input.VersionsDir)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing ListVersions request: {{err}}", err)
+	}
+
+	return versions, nil
+}
+
+// RollbackVersionInput represents parameters to a RollbackVersion operation.
+type RollbackVersionInput struct {
+	ObjectPath  string
+	VersionsDir string
+
+	// Version to link ObjectPath to. If empty, the version preceding the
+	// current one is used.
+	Version string
+}
+
+// RollbackVersion atomically points ObjectPath at an earlier version.
+func (s *VersionsClient) Rollback(ctx context.Context, input *RollbackVersionInput) (*ObjectVersion, error) {
+	versions, err := s.list(ctx, input.ObjectPath, input.VersionsDir)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error executing RollbackVersion request: {{err}}", err)
+	}
+
+	var target *ObjectVersion
+	if input.Version != "" {
+		for _, version := range versions {
+			if version.Version == input.Version {
+				target = version
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("Error executing RollbackVersion request: version %q of %s not found",
+				input.Version, input.ObjectPath)
+		}
+	} else {
+		for i, version := range versions {
+			if version.Current && i+1 < len(versions) {
+				target = versions[i+1]
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("Error executing RollbackVersion request: no version of %s precedes the current one",
+				input.ObjectPath)
+		}
+	}
+
+	if err := s.link(ctx, input.ObjectPath, target.Path); err != nil {
+		return nil, errwrap.Wrapf("Error executing RollbackVersion request: {{err}}", err)
+	}
+
+	for _, version := range versions {
+		version.Current = version == target
+	}
+
+	return target, nil
+}
+
+// link points objectPath at versionPath.
+func (s *VersionsClient) link(ctx context.Context, objectPath, versionPath string) error {
+	snapLinks := &SnapLinksClient{s.client}
+	return snapLinks.Put(ctx, &PutSnapLinkInput{
+		LinkPath:   objectPath,
+		SourcePath: fmt.Sprintf("/%s%s", s.client.AccountName, versionPath),
+	})
+}
+
+func (s *VersionsClient) list(ctx context.Context, objectPath, dir string) ([]*ObjectVersion, error) {
+	dir = versionsDir(objectPath, dir)
+	dirClient := &DirectoryClient{s.client}
+	entries, err := dirClient.listAll(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*ObjectVersion, 0, len(entries))
+	for _, entry := range entries {
+		// Only objects named by PutVersion are versions; the directory may
+		// hold other objects.
+		if entry.Type != "object" {
+			continue
+		}
+		if _, err := time.Parse(versionNameFormat, entry.Name); err != nil {
+			continue
+		}
+		versions = append(versions, &ObjectVersion{
+			Version:      entry.Name,
+			Path:         fmt.Sprintf("%s/%s", dir, entry.Name),
+			ETag:         entry.ETag,
+			Size:         entry.Size,
+			ModifiedTime: entry.ModifiedTime,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+
+	// A SnapLink shares the ETag of the object it was created from, which
+	// identifies the version currently in place.
+	objClient := &ObjectsClient{s.client}
+	info, err := objClient.GetInfo(ctx, &GetInfoInput{
+		ObjectPath: objectPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if version.ETag == info.ETag {
+			version.Current = true
+			break
+		}
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func putTestVersion(t *testing.T, c *StorageClient, data string) *ObjectVersion {
+	version, err := c.Versions().Put(context.Background(), &PutVersionInput{
+		ObjectPath:    "/stor/app.conf",
+		ContentLength: uint64(len(data)),
+		ObjectReader:  strings.NewReader(data),
+	})
+	if err != nil {
+		t.Fatalf("error putting version: %s", err)
+	}
+	return version
+}
+
+func TestVersions(t *testing.T) {
+	c, manta := testStorageClient(t)
+
+	v1 := putTestVersion(t, c, "v1")
+	v2 := putTestVersion(t, c, "v2")
+	if v1.Version >= v2.Version || !strings.HasPrefix(v2.Path, "/stor/app.conf.versions/") {
+		t.Fatalf("unexpected versions %+v, %+v", v1, v2)
+	}
+	if string(manta.get("/acct/stor/app.conf").data) != "v2" {
+		t.Fatal("expected the stable path to link to the latest version")
+	}
+
+	// Objects which were not written by Put are not versions.
+	manta.put("/acct/stor/app.conf.versions/README", "not a version")
+
+	versions, err := c.Versions().List(context.Background(), &ListVersionsInput{ObjectPath: "/stor/app.conf"})
+	if err != nil {
+		t.Fatalf("error listing versions: %s", err)
+	}
+	if len(versions) != 2 || versions[0].Version != v2.Version || versions[1].Version != v1.Version {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+	if !versions[0].Current || versions[1].Current {
+		t.Fatal("expected the latest version to be current")
+	}
+
+	rolledBack, err := c.Versions().Rollback(context.Background(), &RollbackVersionInput{ObjectPath: "/stor/app.conf"})
+	if err != nil {
+		t.Fatalf("error rolling back: %s", err)
+	}
+	if rolledBack.Version != v1.Version || string(manta.get("/acct/stor/app.conf").data) != "v1" {
+		t.Fatalf("expected rollback to v1, got %+v", rolledBack)
+	}
+
+	// The current version is found by the ETag the SnapLink shares with it.
+	versions, err = c.Versions().List(context.Background(), &ListVersionsInput{ObjectPath: "/stor/app.conf"})
+	if err != nil {
+		t.Fatalf("error listing versions: %s", err)
+	}
+	if versions[0].Current || !versions[1].Current {
+		t.Fatalf("expected v1 to be current after rollback, got %+v, %+v", versions[0], versions[1])
+	}
+
+	if _, err := c.Versions().Rollback(context.Background(), &RollbackVersionInput{ObjectPath: "/stor/app.conf"}); err == nil {
+		t.Fatal("expected error rolling back past the oldest version")
+	}
+
+	rolledBack, err = c.Versions().Rollback(context.Background(), &RollbackVersionInput{
+		ObjectPath: "/stor/app.conf",
+		Version:    v2.Version,
+	})
+	if err != nil || rolledBack.Version != v2.Version {
+		t.Fatalf("error rolling forward to v2: %v", err)
+	}
+	if _, err := c.Versions().Rollback(context.Background(), &RollbackVersionInput{
+		ObjectPath: "/stor/app.conf",
+		Version:    "README",
+	}); err == nil {
+		t.Fatal("expected error rolling back to an object which is not a version")
+	}
+}
+
+func TestVersions_ListPages(t *testing.T) {
+	c, manta := testStorageClient(t)
+	manta.put("/acct/stor/app.conf", "")
+	manta.objects["/acct/stor/app.conf.versions"] = &fakeObject{dir: true}
+
+	const total = directoryPageSize + 10
+	for i := 0; i < total; i++ {
+		manta.put(fmt.Sprintf("/acct/stor/app.conf.versions/20170801T000000.%09dZ", i), "")
+	}
+
+	versions, err := c.Versions().List(context.Background(), &ListVersionsInput{ObjectPath: "/stor/app.conf"})
+	if err != nil {
+		t.Fatalf("error listing versions: %s", err)
+	}
+	if len(versions) != total {
+		t.Fatalf("expected %d versions, got %d", total, len(versions))
+	}
+}
@@ -0,0 +1,39 @@
+// Package testclient provides clients backed by fake API servers for unit
+// tests. It lives apart from testutils, which depends on compute, so that the
+// tests of compute and client can use it without an import cycle.
+package testclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joyent/triton-go/authentication"
+	"github.com/joyent/triton-go/client"
+)
+
+// AccountName is the account of the clients returned by New.
+const AccountName = "acct"
+
+// New starts a server serving handler and returns a client of the account
+// "acct" whose CloudAPI and Manta URLs both point to it. Requests are signed
+// with a new Ed25519 key. The server is closed when the test ends.
+func New(t testing.TB, handler http.Handler) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	keyPair, err := authentication.GenerateKeyPair(authentication.KeyTypeEd25519, 0, "")
+	if err != nil {
+		t.Fatalf("error generating key pair: %s", err)
+	}
+	signer, err := authentication.NewPrivateKeySigner(keyPair.Fingerprint, keyPair.PrivateKeyMaterial, AccountName)
+	if err != nil {
+		t.Fatalf("error creating signer: %s", err)
+	}
+
+	c, err := client.New(server.URL, server.URL, AccountName, signer)
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	return c
+}
@@ -14,5 +14,13 @@ type ClientConfig struct {
 	TritonURL   string
 	MantaURL    string
 	AccountName string
-	Signers     []authentication.Signer
+
+	// Username is the sub-user of AccountName to make requests as. The
+	// Signers must sign with a key of that user.
+	Username string
+
+	// AsRole is a comma-separated list of roles to make requests under.
+	AsRole string
+
+	Signers []authentication.Signer
 }